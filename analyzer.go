@@ -16,6 +16,8 @@
 package gosec
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/build"
@@ -27,10 +29,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/tools/go/packages"
+
+	"github.com/securego/gosec/v2/taint"
 )
 
 // LoadMode controls the amount of details to return when loading the packages
@@ -43,6 +50,9 @@ const LoadMode = packages.NeedName |
 	packages.NeedTypesInfo |
 	packages.NeedSyntax
 
+// allRules is the key of the suppressions which apply to all the rules
+const allRules = "*"
+
 // The Context is populated with data parsed from the source code as it is scanned.
 // It is passed through to all rule functions as they are called. Rules may use
 // this data in conjunction withe the encountered AST node.
@@ -55,8 +65,15 @@ type Context struct {
 	Root         *ast.File
 	Config       Config
 	Imports      *ImportTracker
-	Ignores      []map[string]bool
+	Ignores      []map[string][]SuppressionInfo
 	PassedValues map[string]interface{}
+	Taint        *taint.Analyzer
+	resolved     *resolveCache
+	nosecs       []*nosecDirective
+	generated    string // the handling of the current file when it's generated
+	overlay      map[string][]byte
+	resolving    map[types.Object]bool // the functions and the variables being resolved
+	fingerprints map[string]int        // occurrences of the fingerprints in the file
 }
 
 // Metrics used when reporting information about a scanning run.
@@ -65,20 +82,75 @@ type Metrics struct {
 	NumLines int `json:"lines"`
 	NumNosec int `json:"nosec"`
 	NumFound int `json:"found"`
+	// NumGenerated counts the generated files, which are included in NumFiles
+	NumGenerated int `json:"generated"`
+	// Baseline is set when the issues are compared with a baseline
+	Baseline *BaselineMetrics `json:"baseline,omitempty"`
+	// Profile is set when the rules are profiled
+	Profile *Profile `json:"profile,omitempty"`
+}
+
+// BaselineMetrics counts the issues compared with a baseline
+type BaselineMetrics struct {
+	New       int `json:"new"`
+	Unchanged int `json:"unchanged"`
+	Fixed     int `json:"fixed"`
+}
+
+// merge adds the metrics collected in other to the current metrics
+func (m *Metrics) merge(other *Metrics) {
+	m.NumFiles += other.NumFiles
+	m.NumLines += other.NumLines
+	m.NumNosec += other.NumNosec
+	m.NumFound += other.NumFound
+	m.NumGenerated += other.NumGenerated
+	if other.Profile != nil {
+		if m.Profile == nil {
+			m.Profile = newProfile()
+		}
+		m.Profile.merge(other.Profile)
+	}
 }
 
 // Analyzer object is the main object of gosec. It has methods traverse an AST
 // and invoke the correct checking rules as on each node as required.
 type Analyzer struct {
-	ignoreNosec bool
-	ruleset     RuleSet
-	context     *Context
-	config      Config
-	logger      *log.Logger
-	issues      []*Issue
-	stats       *Metrics
-	errors      map[string][]Error // keys are file paths; values are the golang errors in those files
-	tests       bool
+	ignoreNosec       bool
+	trackSuppressions bool
+	staleNosec        bool
+	profileRules      bool
+	platforms         []Platform
+	platform          *Platform // the platform checked by the analyzer, nil for the current one
+	overlay           map[string][]byte
+	ctx               context.Context // cancels the loading and the checks of the packages
+	suppressed        []*Issue
+	directives        []*nosecDirective
+	failures          []*RuleFailure
+	ruleset           RuleSet
+	context           *Context
+	config            Config
+	logger            *log.Logger
+	issues            []*Issue
+	stats             *Metrics
+	errors            map[string][]Error // keys are file paths; values are the golang errors in those files
+	files             []string           // the checked files
+	tests             bool
+	concurrency       int
+	builders          map[string]RuleBuilder
+	excludes          []*regexp.Regexp // the excluded package directories
+	resolved          *resolveCache
+	cache             *Cache
+}
+
+// packageResult holds the outcome of the analysis of a single package path
+type packageResult struct {
+	issues     []*Issue
+	suppressed []*Issue
+	failures   []*RuleFailure
+	stats      *Metrics
+	errors     map[string][]Error
+	files      []string
+	err        error
 }
 
 // NewAnalyzer builds a new analyzer.
@@ -100,6 +172,10 @@ func NewAnalyzer(conf Config, tests bool, logger *log.Logger) *Analyzer {
 		stats:       &Metrics{},
 		errors:      make(map[string][]Error),
 		tests:       tests,
+		resolved:    newResolveCache(),
+		concurrency: 1,
+		builders:    make(map[string]RuleBuilder),
+		ctx:         context.Background(),
 	}
 }
 
@@ -113,40 +189,313 @@ func (gosec *Analyzer) Config() Config {
 	return gosec.config
 }
 
+// SetConcurrency sets the number of packages which are loaded and checked in
+// parallel by Process. Values lower than 1 are treated as 1.
+func (gosec *Analyzer) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	gosec.concurrency = concurrency
+}
+
+// SetTrackSuppressions enables the reporting of the issues suppressed by #nosec
+// directives. The rules are then evaluated in the suppressed code as well.
+func (gosec *Analyzer) SetTrackSuppressions(track bool) {
+	gosec.trackSuppressions = track
+}
+
+// SetStaleNosec enables the reporting of the #nosec directives, or of the rule IDs
+// listed in them, which don't suppress any issue. The rules are then evaluated in
+// the suppressed code as well.
+func (gosec *Analyzer) SetStaleNosec(stale bool) {
+	gosec.staleNosec = stale
+}
+
+// SetProfileRules enables the collection of the execution time of the rules and
+// of the load time of the packages, which are reported in the metrics
+func (gosec *Analyzer) SetProfileRules(profile bool) {
+	gosec.profileRules = profile
+}
+
+// SetPlatforms sets the platforms for which the packages are loaded and checked.
+// The issues found on several platforms are reported once, along with the list of
+// their platforms. No platform means the current one.
+func (gosec *Analyzer) SetPlatforms(platforms []Platform) {
+	gosec.platforms = platforms
+}
+
+// SetOverlay sets the content of the files which are not saved, keyed by absolute
+// file path. The content replaces the file on disk, or adds a file to its package
+// when it doesn't exist yet. The files are type checked with their package.
+func (gosec *Analyzer) SetOverlay(overlay map[string][]byte) {
+	gosec.overlay = overlay
+}
+
+// SetCache enables the reuse of the results of the packages which didn't change
+// since they were stored in the cache. A nil cache disables it.
+func (gosec *Analyzer) SetCache(cache *Cache) {
+	gosec.cache = cache
+}
+
+// SetExcludedDirs sets the regular expressions of the package directories which
+// are not checked, see ExcludedDirsRegExp
+func (gosec *Analyzer) SetExcludedDirs(excludes []*regexp.Regexp) {
+	gosec.excludes = excludes
+}
+
 // LoadRules instantiates all the rules to be used when analyzing source
-// packages
+// packages. The rules whose builder panics are reported as rule failures.
 func (gosec *Analyzer) LoadRules(ruleDefinitions map[string]RuleBuilder) {
 	for id, def := range ruleDefinitions {
-		r, nodes := def(id, gosec.config)
+		r, nodes, failure := buildRule(id, def, gosec.config)
+		if failure != nil {
+			gosec.logger.Printf("Rule error: %s => %s\n", id, failure.Err)
+			gosec.failures = append(gosec.failures, failure)
+			continue
+		}
 		gosec.ruleset.Register(r, nodes...)
+		gosec.builders[id] = def
 	}
 }
 
-// Process kicks off the analysis process for a given package
+// buildRule instantiates a rule, recovering from a panic of its builder
+func buildRule(id string, def RuleBuilder, conf Config) (rule Rule, nodes []ast.Node, failure *RuleFailure) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure = &RuleFailure{RuleID: id, Err: fmt.Sprintf("panic: %v", r), Stack: string(debug.Stack())}
+		}
+	}()
+	rule, nodes = def(id, conf)
+	return rule, nodes, nil
+}
+
+// Process kicks off the analysis process for the given package patterns, the
+// patterns ending with "..." matching the packages of the sub-directories too.
+// The patterns which belong to a module are passed to a single load per module,
+// and the packages are then checked by concurrent workers, each one with its own
+// rules and context. The packages outside of a module are loaded by the workers
+// beforehand.
+// The results are merged in the order of the package directories so that the
+// report does not depend on the number of workers.
 func (gosec *Analyzer) Process(buildTags []string, packagePaths ...string) error {
+	return gosec.ProcessContext(context.Background(), buildTags, packagePaths...)
+}
+
+// ProcessContext analyzes the given packages as Process, until the context is
+// canceled. The packages which are not checked yet are then skipped, and the
+// error of the context is returned.
+func (gosec *Analyzer) ProcessContext(ctx context.Context, buildTags []string, packagePaths ...string) error {
+	gosec.ctx = ctx
+	if len(gosec.platforms) > 0 {
+		return gosec.processPlatforms(buildTags, packagePaths...)
+	}
+	return gosec.process(buildTags, packagePaths...)
+}
+
+// process loads and checks the packages for a single platform
+func (gosec *Analyzer) process(buildTags []string, packagePaths ...string) error {
+	// the functions and the packages of the load are not kept after the check
+	defer gosec.resolved.clear()
+
+	pkgJobs, groups, err := groupJobs(packagePaths, gosec.excludes)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if err := gosec.loadModule(buildTags, group); err != nil {
+			if ctxErr := gosec.ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		pkgJobs = append(pkgJobs, group.jobs...)
+	}
+
+	// every package is loaded and registered before the checks start, so that the
+	// resolution of the calls across the packages doesn't depend on their order
+	var unloaded []*packageJob
+	for _, job := range pkgJobs {
+		if !job.loaded {
+			unloaded = append(unloaded, job)
+		}
+	}
+	for _, result := range gosec.runJobs(unloaded, func(worker *Analyzer, job *packageJob) *packageResult {
+		worker.loadJob(buildTags, job)
+		return worker.drain()
+	}) {
+		gosec.merge(result)
+		if result.err != nil {
+			sortErrors(gosec.errors)
+			return result.err
+		}
+	}
+
+	results := gosec.runJobs(pkgJobs, func(worker *Analyzer, job *packageJob) *packageResult {
+		return worker.processJob(job)
+	})
+	for _, result := range results {
+		gosec.merge(result)
+		if result.err != nil {
+			sortErrors(gosec.errors)
+			return result.err
+		}
+	}
+	sortErrors(gosec.errors)
+	return nil
+}
+
+// runJobs runs a function on the jobs with concurrent workers, and returns the
+// results in the order of the jobs. The jobs which are not run yet when the
+// context is canceled get the error of the context.
+func (gosec *Analyzer) runJobs(pkgJobs []*packageJob, run func(worker *Analyzer, job *packageJob) *packageResult) []*packageResult {
+	jobs := make(chan int, len(pkgJobs))
+	for i := range pkgJobs {
+		jobs <- i
+	}
+	close(jobs)
+
+	workers := gosec.concurrency
+	if workers > len(pkgJobs) {
+		workers = len(pkgJobs)
+	}
+	results := make([]*packageResult, len(pkgJobs))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := gosec.fork()
+			for i := range jobs {
+				if err := worker.ctx.Err(); err != nil {
+					results[i] = worker.drain()
+					results[i].err = err
+					continue
+				}
+				results[i] = run(worker, pkgJobs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fork creates an analyzer with the same configuration and rules, but with its
+// own context and results. The rules are instantiated again because they might
+// keep state while matching.
+func (gosec *Analyzer) fork() *Analyzer {
+	worker := NewAnalyzer(gosec.config, gosec.tests, gosec.logger)
+	worker.ignoreNosec = gosec.ignoreNosec
+	worker.trackSuppressions = gosec.trackSuppressions
+	worker.staleNosec = gosec.staleNosec
+	worker.profileRules = gosec.profileRules
+	worker.platform = gosec.platform
+	worker.overlay = gosec.overlay
+	worker.excludes = gosec.excludes
+	worker.ctx = gosec.ctx
+	worker.resolved = gosec.resolved
+	worker.cache = gosec.cache
+	worker.LoadRules(gosec.builders)
+	return worker
+}
+
+// processJob checks the packages of a job, unless its results are cached, and
+// returns the results
+func (gosec *Analyzer) processJob(job *packageJob) *packageResult {
+	if job.result != nil {
+		return job.result
+	}
+	result := gosec.checkPackages(job.pkgs)
+	if job.key != "" && result.err == nil {
+		if err := gosec.cache.put(job.key, result); err != nil {
+			gosec.logger.Printf("Error caching %s: %v", job.path, err)
+		}
+	}
+	return result
+}
+
+// loadJob loads the packages of a job which doesn't belong to a module, unless
+// its results are cached, and registers them
+func (gosec *Analyzer) loadJob(buildTags []string, job *packageJob) {
+	job.loaded = true
+	if gosec.cache != nil {
+		key, err := gosec.cacheKey(buildTags, job.path)
+		if err != nil {
+			gosec.logger.Printf("Not caching %s: %v", job.path, err)
+		} else if result, ok := gosec.cache.get(key); ok {
+			gosec.logger.Println("Cached directory:", job.path)
+			job.result = result
+			return
+		} else {
+			job.key = key
+		}
+	}
 	config := &packages.Config{
 		Mode:       LoadMode,
 		BuildFlags: buildTags,
 		Tests:      gosec.tests,
+		Env:        gosec.loadEnv(),
+		Overlay:    gosec.overlay,
+		Context:    gosec.ctx,
 	}
+	pkgs, err := gosec.load(job.path, config)
+	if err != nil {
+		gosec.AppendError(job.path, err)
+	}
+	gosec.resolved.addPackages(pkgs)
+	job.pkgs = pkgs
+}
 
-	for _, pkgPath := range packagePaths {
-		pkgs, err := gosec.load(pkgPath, config)
-		if err != nil {
-			gosec.AppendError(pkgPath, err)
+// checkPackages checks the loaded packages
+func (gosec *Analyzer) checkPackages(pkgs []*packages.Package) *packageResult {
+	for _, pkg := range pkgs {
+		if err := gosec.ctx.Err(); err != nil {
+			result := gosec.drain()
+			result.err = err
+			return result
 		}
-		for _, pkg := range pkgs {
-			if pkg.Name != "" {
-				err := gosec.ParseErrors(pkg)
-				if err != nil {
-					return fmt.Errorf("parsing errors in pkg %q: %v", pkg.Name, err)
-				}
-				gosec.Check(pkg)
+		if pkg.Name != "" {
+			err := gosec.ParseErrors(pkg)
+			if err != nil {
+				result := gosec.drain()
+				result.err = fmt.Errorf("parsing errors in pkg %q: %v", pkg.Name, err)
+				return result
 			}
+			gosec.Check(pkg)
 		}
 	}
-	sortErrors(gosec.errors)
-	return nil
+	return gosec.drain()
+}
+
+// drain returns the results collected so far and clears them from the analyzer
+func (gosec *Analyzer) drain() *packageResult {
+	result := &packageResult{
+		issues:     gosec.issues,
+		suppressed: gosec.suppressed,
+		failures:   gosec.failures,
+		stats:      gosec.stats,
+		errors:     gosec.errors,
+		files:      gosec.files,
+	}
+	gosec.issues = make([]*Issue, 0, 16)
+	gosec.suppressed = nil
+	gosec.failures = nil
+	gosec.files = nil
+	gosec.directives = nil
+	gosec.stats = &Metrics{}
+	gosec.errors = make(map[string][]Error)
+	return result
+}
+
+// merge appends the results of a package to the results of the analyzer
+func (gosec *Analyzer) merge(result *packageResult) {
+	gosec.issues = append(gosec.issues, result.issues...)
+	gosec.suppressed = append(gosec.suppressed, result.suppressed...)
+	gosec.failures = append(gosec.failures, result.failures...)
+	gosec.files = append(gosec.files, result.files...)
+	gosec.stats.merge(result.stats)
+	for file, errs := range result.errors {
+		gosec.errors[file] = append(gosec.errors[file], errs...)
+	}
 }
 
 func (gosec *Analyzer) load(pkgPath string, conf *packages.Config) ([]*packages.Package, error) {
@@ -157,13 +506,37 @@ func (gosec *Analyzer) load(pkgPath string, conf *packages.Config) ([]*packages.
 	}
 
 	gosec.logger.Println("Import directory:", abspath)
-	// step 1/3 create build context.
+	packageFiles, err := gosec.packageFiles(pkgPath, conf.BuildFlags)
+	if err != nil {
+		return []*packages.Package{}, err
+	}
+
+	// remove build tags from conf to proceed build correctly.
+	conf.BuildFlags = nil
+	start := time.Now()
+	pkgs, err := packages.Load(conf, packageFiles...)
+	if gosec.profileRules {
+		gosec.profile().addPackage(pkgPath, time.Since(start))
+	}
+	if err != nil {
+		return []*packages.Package{}, fmt.Errorf("loading files from package %q: %v", pkgPath, err)
+	}
+	return pkgs, nil
+}
+
+// packageFiles lists the files of the package which are analyzed with the given build tags
+func (gosec *Analyzer) packageFiles(pkgPath string, buildTags []string) ([]string, error) {
+	// step 1/2 create build context.
 	buildD := build.Default
-	// step 2/3: add build tags to get env dependent files into basePackage.
-	buildD.BuildTags = conf.BuildFlags
+	// step 2/2: add build tags to get env dependent files into basePackage.
+	buildD.BuildTags = buildTags
+	if gosec.platform != nil {
+		buildD.GOOS = gosec.platform.GOOS
+		buildD.GOARCH = gosec.platform.GOARCH
+	}
 	basePackage, err := buildD.ImportDir(pkgPath, build.ImportComment)
 	if err != nil {
-		return []*packages.Package{}, fmt.Errorf("importing dir %q: %v", pkgPath, err)
+		return nil, fmt.Errorf("importing dir %q: %v", pkgPath, err)
 	}
 
 	var packageFiles []string
@@ -182,19 +555,17 @@ func (gosec *Analyzer) load(pkgPath string, conf *packages.Config) ([]*packages.
 			packageFiles = append(packageFiles, path.Join(pkgPath, filename))
 		}
 	}
-
-	// step 3/3 remove build tags from conf to proceed build correctly.
-	conf.BuildFlags = nil
-	pkgs, err := packages.Load(conf, packageFiles...)
-	if err != nil {
-		return []*packages.Package{}, fmt.Errorf("loading files from package %q: %v", pkgPath, err)
-	}
-	return pkgs, nil
+	return append(packageFiles, gosec.overlayFiles(pkgPath, packageFiles)...), nil
 }
 
 // Check runs analysis on the given package
 func (gosec *Analyzer) Check(pkg *packages.Package) {
 	gosec.logger.Println("Checking package:", pkg.Name)
+	var taintAnalyzer *taint.Analyzer
+	if enabled, err := gosec.config.IsGlobalEnabled(TaintAnalysis); err == nil && enabled {
+		taintAnalyzer = taint.New(gosec.taintConfig(), pkg.Fset, pkg.Types, pkg.Syntax, pkg.TypesInfo)
+	}
+	generatedMode := gosec.generatedMode()
 	for _, file := range pkg.Syntax {
 		checkedFile := pkg.Fset.File(file.Pos()).Name()
 		// Skip the no-Go file from analysis (e.g. a Cgo files is expanded in 3 different files
@@ -202,6 +573,17 @@ func (gosec *Analyzer) Check(pkg *packages.Package) {
 		if filepath.Ext(checkedFile) != ".go" {
 			continue
 		}
+		gosec.context.generated = ""
+		if IsGeneratedFile(file) {
+			gosec.stats.NumGenerated++
+			gosec.context.generated = generatedMode
+			if generatedMode == GeneratedSkip {
+				gosec.logger.Println("Skipping generated file:", checkedFile)
+				gosec.stats.NumFiles++
+				gosec.stats.NumLines += pkg.Fset.File(file.Pos()).LineCount()
+				continue
+			}
+		}
 		gosec.logger.Println("Checking file:", checkedFile)
 		gosec.context.FileSet = pkg.Fset
 		gosec.context.Config = gosec.config
@@ -213,12 +595,41 @@ func (gosec *Analyzer) Check(pkg *packages.Package) {
 		gosec.context.Imports = NewImportTracker()
 		gosec.context.Imports.TrackFile(file)
 		gosec.context.PassedValues = make(map[string]interface{})
+		gosec.context.Taint = taintAnalyzer
+		gosec.context.resolved = gosec.resolved
+		gosec.context.nosecs = nil
+		gosec.context.overlay = gosec.overlay
+		gosec.context.fingerprints = make(map[string]int)
+		gosec.directives = nil
 		ast.Walk(gosec, file)
+		gosec.reportStaleNosec()
+		gosec.files = append(gosec.files, checkedFile)
 		gosec.stats.NumFiles++
 		gosec.stats.NumLines += pkg.Fset.File(file.Pos()).LineCount()
 	}
 }
 
+// taintConfig builds the configuration of the taint analysis by extending the
+// default one with the taint section of the gosec configuration
+func (gosec *Analyzer) taintConfig() *taint.Config {
+	conf := taint.DefaultConfig()
+	section, err := gosec.config.Get(TaintSection)
+	if err != nil {
+		return conf
+	}
+	var custom taint.Config
+	data, err := json.Marshal(section)
+	if err == nil {
+		err = json.Unmarshal(data, &custom)
+	}
+	if err != nil {
+		gosec.logger.Printf("Invalid %s configuration: %s", TaintSection, err)
+		return conf
+	}
+	conf.Merge(&custom)
+	return conf
+}
+
 // ParseErrors parses the errors from given package
 func (gosec *Analyzer) ParseErrors(pkg *packages.Package) error {
 	if len(pkg.Errors) == 0 {
@@ -268,8 +679,10 @@ func (gosec *Analyzer) AppendError(file string, err error) {
 	gosec.errors[file] = errors
 }
 
-// ignore a node (and sub-tree) if it is tagged with a nosec tag comment
-func (gosec *Analyzer) ignore(n ast.Node) ([]string, bool) {
+// ignore checks if a node (and sub-tree) is tagged with a nosec tag comment. It
+// returns the directive holding the IDs of the suppressed rules, which are empty
+// when all the rules are suppressed, and the justification given after "--".
+func (gosec *Analyzer) ignore(n ast.Node) *nosecDirective {
 	if groups, ok := gosec.context.Comments[n]; ok && !gosec.ignoreNosec {
 
 		// Checks if an alternative for #nosec is set and, if not, uses the default.
@@ -280,32 +693,66 @@ func (gosec *Analyzer) ignore(n ast.Node) ([]string, bool) {
 		}
 
 		for _, group := range groups {
+			text := group.Text()
+			tag := strings.Index(text, noSecDefaultTag)
+			if tag < 0 {
+				tag = strings.Index(text, noSecAlternativeTag)
+			}
 
-			foundDefaultTag := strings.Contains(group.Text(), noSecDefaultTag)
-			foundAlternativeTag := strings.Contains(group.Text(), noSecAlternativeTag)
-
-			if foundDefaultTag || foundAlternativeTag {
+			if tag >= 0 {
 				gosec.stats.NumNosec++
 
-				// Pull out the specific rules that are listed to be ignored.
-				re := regexp.MustCompile(`(G\d{3})`)
-				matches := re.FindAllStringSubmatch(group.Text(), -1)
-
-				// If no specific rules were given, ignore everything.
-				if len(matches) == 0 {
-					return nil, true
+				// The justification follows the directive, e.g. "#nosec G304 -- embedded file"
+				directive := text
+				suppression := SuppressionInfo{Kind: InSource}
+				if i := strings.Index(text[tag:], "--"); i >= 0 {
+					directive = text[:tag+i]
+					suppression.Justification = strings.TrimSpace(text[tag+i+2:])
 				}
+				if suppression.Justification == "" {
+					gosec.requireJustification(group)
+				}
+
+				// Pull out the specific rules that are listed to be ignored, including the
+				// rules registered by other packages or by plugins (e.g. G101, C101). The
+				// other words looking like rule IDs, e.g. SHA256, are part of the comment.
+				re := regexp.MustCompile(`\b([A-Z]+\d{3,})\b`)
+				matches := re.FindAllStringSubmatch(directive, -1)
 
-				// Find the rule IDs to ignore.
+				// Find the rule IDs to ignore. If no specific rules were given, ignore everything.
 				var ignores []string
 				for _, v := range matches {
-					ignores = append(ignores, v[1])
+					if gosec.isRuleID(v[1]) {
+						ignores = append(ignores, v[1])
+					}
 				}
-				return ignores, false
+				nosec := &nosecDirective{group: group, rules: ignores, suppression: suppression}
+				gosec.directives = append(gosec.directives, nosec)
+				return nosec
 			}
 		}
 	}
-	return nil, false
+	return nil
+}
+
+// isRuleID checks whether the ID is the one of a loaded rule, or of a rule known
+// by its CWE: a rule provided by gosec, a registered rule or the rule of a plugin
+func (gosec *Analyzer) isRuleID(id string) bool {
+	if _, ok := gosec.builders[id]; ok {
+		return true
+	}
+	return GetCweByRule(id) != nil
+}
+
+// requireJustification reports an error for a #nosec directive without
+// justification when justifications are required
+func (gosec *Analyzer) requireJustification(group *ast.CommentGroup) {
+	if enabled, err := gosec.config.IsGlobalEnabled(NoSecJustification); err != nil || !enabled {
+		return
+	}
+	pos := gosec.context.FileSet.Position(group.Pos())
+	gosec.errors[pos.Filename] = append(gosec.errors[pos.Filename],
+		*NewError(pos.Line, pos.Column, "#nosec directive without justification"))
 }
 
 // Visit runs the gosec visitor logic over an AST created by parsing go code.
@@ -316,44 +763,59 @@ func (gosec *Analyzer) Visit(n ast.Node) ast.Visitor {
 		if len(gosec.context.Ignores) > 0 {
 			gosec.context.Ignores = gosec.context.Ignores[1:]
 		}
+		if len(gosec.context.nosecs) > 0 {
+			gosec.context.nosecs = gosec.context.nosecs[1:]
+		}
 		return gosec
 	}
 
-	// Get any new rule exclusions.
-	ignoredRules, ignoreAll := gosec.ignore(n)
-	if ignoreAll {
-		return nil
-	}
+	// Get any new rule exclusions. The suppressed code is still evaluated so that
+	// the fingerprints of the issues don't depend on the directives.
+	nosec := gosec.ignore(n)
 
 	// Now create the union of exclusions.
-	ignores := map[string]bool{}
+	ignores := map[string][]SuppressionInfo{}
 	if len(gosec.context.Ignores) > 0 {
 		for k, v := range gosec.context.Ignores[0] {
 			ignores[k] = v
 		}
 	}
 
-	for _, v := range ignoredRules {
-		ignores[v] = true
+	if nosec != nil {
+		ignoredRules := nosec.rules
+		if len(ignoredRules) == 0 {
+			ignoredRules = []string{allRules}
+		}
+		for _, v := range ignoredRules {
+			ignores[v] = append(append([]SuppressionInfo{}, ignores[v]...), nosec.suppression)
+		}
 	}
 
 	// Push the new set onto the stack.
-	gosec.context.Ignores = append([]map[string]bool{ignores}, gosec.context.Ignores...)
+	gosec.context.Ignores = append([]map[string][]SuppressionInfo{ignores}, gosec.context.Ignores...)
+	gosec.context.nosecs = append([]*nosecDirective{nosec}, gosec.context.nosecs...)
 
 	// Track aliased and initialization imports
 	gosec.context.Imports.TrackImport(n)
 
 	for _, rule := range gosec.ruleset.RegisteredFor(n) {
-		if _, ok := ignores[rule.ID()]; ok {
-			continue
+		suppressions := ignores[rule.ID()]
+		if all := ignores[allRules]; len(all) > 0 {
+			suppressions = append(append([]SuppressionInfo{}, suppressions...), all...)
 		}
-		issue, err := rule.Match(n, gosec.context)
-		if err != nil {
-			file, line := GetLocation(n, gosec.context)
-			file = path.Base(file)
-			gosec.logger.Printf("Rule error: %v => %s (%s:%d)\n", reflect.TypeOf(rule), err, file, line)
-		}
-		if issue != nil {
+		for _, issue := range gosec.match(rule, n) {
+			gosec.context.countFingerprint(issue)
+			if gosec.context.generated != "" {
+				markGenerated(issue, gosec.context.generated)
+			}
+			if len(suppressions) > 0 {
+				gosec.markUsedNosec(rule.ID())
+				if gosec.trackSuppressions {
+					issue.Suppressions = suppressions
+					gosec.suppressed = append(gosec.suppressed, issue)
+				}
+				continue
+			}
 			gosec.issues = append(gosec.issues, issue)
 			gosec.stats.NumFound++
 		}
@@ -361,6 +823,52 @@ func (gosec *Analyzer) Visit(n ast.Node) ast.Visitor {
 	return gosec
 }
 
+// match runs a rule on a node. The errors and the panics of the rule are recorded
+// as rule failures, so that a faulty rule doesn't stop the scan.
+func (gosec *Analyzer) match(rule Rule, n ast.Node) (issues []*Issue) {
+	if gosec.profileRules {
+		start := time.Now()
+		defer func() {
+			gosec.profile().addRule(rule.ID(), time.Since(start), len(issues))
+		}()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			issues = nil
+			gosec.addRuleFailure(rule, n, fmt.Errorf("panic: %v", r), string(debug.Stack()))
+		}
+	}()
+	issues, err := matchAll(rule, n, gosec.context)
+	if err != nil {
+		gosec.addRuleFailure(rule, n, err, "")
+	}
+	return issues
+}
+
+func (gosec *Analyzer) addRuleFailure(rule Rule, n ast.Node, err error, stack string) {
+	pos := gosec.context.FileSet.Position(n.Pos())
+	gosec.logger.Printf("Rule error: %v => %s (%s:%d)\n", reflect.TypeOf(rule), err, path.Base(pos.Filename), pos.Line)
+	gosec.failures = append(gosec.failures, &RuleFailure{
+		RuleID: rule.ID(),
+		File:   pos.Filename,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Err:    err.Error(),
+		Stack:  stack,
+	})
+}
+
+// RuleFailures returns the errors and the panics of the rules
+func (gosec *Analyzer) RuleFailures() []*RuleFailure {
+	return gosec.failures
+}
+
+// Suppressed returns the issues suppressed by #nosec directives, which are only
+// collected when the suppressions are tracked
+func (gosec *Analyzer) Suppressed() []*Issue {
+	return gosec.suppressed
+}
+
 // Report returns the current issues discovered and the metrics about the scan
 func (gosec *Analyzer) Report() ([]*Issue, *Metrics, map[string][]Error) {
 	return gosec.issues, gosec.stats, gosec.errors
@@ -370,6 +878,11 @@ func (gosec *Analyzer) Report() ([]*Issue, *Metrics, map[string][]Error) {
 func (gosec *Analyzer) Reset() {
 	gosec.context = &Context{}
 	gosec.issues = make([]*Issue, 0, 16)
+	gosec.suppressed = nil
+	gosec.failures = nil
+	gosec.files = nil
 	gosec.stats = &Metrics{}
 	gosec.ruleset = NewRuleSet()
+	gosec.builders = make(map[string]RuleBuilder)
+	gosec.resolved = newResolveCache()
 }
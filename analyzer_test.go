@@ -2,9 +2,12 @@ package gosec_test
 
 import (
 	"errors"
+	"fmt"
+	"go/ast"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/securego/gosec/v2"
@@ -102,6 +105,474 @@ var _ = Describe("Analyzer", func() {
 			Expect(metrics.NumFiles).To(Equal(2))
 		})
 
+		It("should load the packages of a module together", func() {
+			root, err := ioutil.TempDir("", "module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			files := map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"util/util.go": `
+				package util
+				import "crypto/md5"
+				func Hash(data []byte) [md5.Size]byte {
+					return md5.Sum(data)
+				}`,
+				"util/util_test.go": `
+				package util
+				import "testing"
+				func TestHash(t *testing.T) {
+					Hash(nil)
+				}`,
+				"cmd/app/main.go": `
+				package main
+				import "example.com/app/util"
+				func main() {
+					println(util.Hash(nil)[0])
+				}`,
+			}
+			writeFiles(root, files)
+
+			moduleLogger, logs := testutils.NewLogger()
+			customAnalyzer := gosec.NewAnalyzer(nil, true, moduleLogger)
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = customAnalyzer.Process(buildTags, filepath.Join(root, "cmd", "app"), filepath.Join(root, "util"))
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, errors := customAnalyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(metrics.NumFiles).To(Equal(3))
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].File).To(HaveSuffix("util.go"))
+			Expect(strings.Count(logs.String(), "Import module:")).To(Equal(1))
+		})
+
+		It("should load the packages matched by a recursive pattern with the module", func() {
+			root, err := ioutil.TempDir("", "module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			writeFiles(root, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"util/util.go": `
+				package util
+				import "crypto/md5"
+				func Hash(data []byte) [md5.Size]byte {
+					return md5.Sum(data)
+				}`,
+				"generated/hash.go": `
+				package generated
+				import "crypto/md5"
+				func Hash(data []byte) [md5.Size]byte {
+					return md5.Sum(data)
+				}`,
+				"docs/README.md": "no package",
+			})
+
+			moduleLogger, logs := testutils.NewLogger()
+			customAnalyzer := gosec.NewAnalyzer(nil, false, moduleLogger)
+			customAnalyzer.SetExcludedDirs(gosec.ExcludedDirsRegExp([]string{"generated"}))
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = customAnalyzer.Process(buildTags, root+"/...")
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, errors := customAnalyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(metrics.NumFiles).To(Equal(1))
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].File).To(HaveSuffix("util.go"))
+			Expect(strings.Count(logs.String(), "Import module:")).To(Equal(1))
+		})
+
+		It("should load the nested modules matched by a recursive pattern from their own root", func() {
+			root, err := ioutil.TempDir("", "module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			hash := `
+				package %s
+				import "crypto/md5"
+				func Hash(data []byte) [md5.Size]byte {
+					return md5.Sum(data)
+				}`
+			writeFiles(root, map[string]string{
+				"go.mod":                 "module example.com/app\n\ngo 1.16\n",
+				"hash.go":                fmt.Sprintf(hash, "app"),
+				"tools/go.mod":           "module example.com/tools\n\ngo 1.16\n",
+				"tools/hash/hash.go":     fmt.Sprintf(hash, "hash"),
+				"testdata/mod/go.mod":    "module example.com/mod\n\ngo 1.16\n",
+				"testdata/mod/hash.go":   fmt.Sprintf(hash, "mod"),
+				".hidden/go.mod":         "module example.com/hidden\n\ngo 1.16\n",
+				".hidden/hidden/hash.go": fmt.Sprintf(hash, "hidden"),
+			})
+
+			moduleLogger, logs := testutils.NewLogger()
+			customAnalyzer := gosec.NewAnalyzer(nil, false, moduleLogger)
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = customAnalyzer.Process(buildTags, root+"/...")
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, errors := customAnalyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(issues).To(HaveLen(2))
+			Expect(issues[0].File).To(Equal(filepath.Join(root, "hash.go")))
+			Expect(issues[1].File).To(Equal(filepath.Join(root, "tools", "hash", "hash.go")))
+			Expect(strings.Count(logs.String(), "Import module:")).To(Equal(2))
+		})
+
+		It("should resolve the calls to the functions of the other packages of the module", func() {
+			root, err := ioutil.TempDir("", "module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			writeFiles(root, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"shell/shell.go": `
+				package shell
+				import "os"
+				const name = "sh"
+				func Name() string {
+					return name
+				}
+				func UserName() string {
+					return os.Getenv("SHELL")
+				}`,
+				"main.go": `
+				package main
+				import (
+					"os/exec"
+					"example.com/app/shell"
+				)
+				func main() {
+					_ = exec.Command(shell.Name()).Run()
+					_ = exec.Command(shell.UserName()).Run()
+				}`,
+			})
+
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G204")).Builders())
+			err = analyzer.Process(buildTags, root+"/...")
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, errors := analyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].Line).To(Equal("9"))
+		})
+
+		It("should resolve the calls to the functions of the cached packages of the module", func() {
+			root, err := ioutil.TempDir("", "module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			main := `
+				package main
+				import (
+					"os/exec"
+					"example.com/app/shell"
+				)
+				func main() {
+					_ = exec.Command(shell.Name()).Run()
+				}`
+			writeFiles(root, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"shell/shell.go": `
+				package shell
+				func Name() string {
+					return "sh"
+				}`,
+				"main.go": main,
+			})
+
+			dir, err := ioutil.TempDir("", "cache")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			cache, err := gosec.NewCache(dir, "test")
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, source := range []string{main, main + "\nfunc unused() {}\n"} {
+				writeFiles(root, map[string]string{"main.go": source})
+				cachedLogger, _ := testutils.NewLogger()
+				cached := gosec.NewAnalyzer(nil, tests, cachedLogger)
+				cached.SetCache(cache)
+				cached.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G204")).Builders())
+				Expect(cached.Process(buildTags, root+"/...")).Should(Succeed())
+				issues, _, errors := cached.Report()
+				Expect(errors).To(BeEmpty())
+				Expect(issues).To(BeEmpty())
+			}
+		})
+
+		It("should check each platform and merge the issues found on several platforms", func() {
+			root, err := ioutil.TempDir("", "platforms")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			writeFiles(root, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"hash.go": `
+				package app
+				import "crypto/md5"
+				func Hash(data []byte) [md5.Size]byte {
+					return md5.Sum(data)
+				}`,
+				"hash_windows.go": `
+				package app
+				import "crypto/sha1"
+				func WindowsHash(data []byte) [sha1.Size]byte {
+					return sha1.Sum(data)
+				}`,
+			})
+
+			var platforms []gosec.Platform
+			for _, s := range []string{"linux/amd64", "windows/amd64"} {
+				platform, err := gosec.ParsePlatform(s)
+				Expect(err).ShouldNot(HaveOccurred())
+				platforms = append(platforms, platform)
+			}
+			analyzer.SetPlatforms(platforms)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = analyzer.Process(buildTags, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, errors := analyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(metrics.NumFound).To(Equal(2))
+			Expect(issues).To(HaveLen(2))
+			Expect(issues[0].File).To(HaveSuffix("hash.go"))
+			Expect(issues[0].Platforms).To(Equal([]string{"linux/amd64", "windows/amd64"}))
+			Expect(issues[1].File).To(HaveSuffix("hash_windows.go"))
+			Expect(issues[1].Platforms).To(Equal([]string{"windows/amd64"}))
+		})
+
+		It("should report the stale nosec directives only when they are stale on every platform", func() {
+			root, err := ioutil.TempDir("", "platforms")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(root)
+			writeFiles(root, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.16\n",
+				"run.go": `
+				package app
+				func Run() {
+					cleanup() // #nosec G104
+					println("done") // #nosec G104
+				}`,
+				"cleanup_linux.go": `
+				package app
+				func cleanup() error {
+					return nil
+				}`,
+				"cleanup_windows.go": `
+				package app
+				func cleanup() {}`,
+			})
+
+			var platforms []gosec.Platform
+			for _, s := range []string{"linux/amd64", "windows/amd64"} {
+				platform, err := gosec.ParsePlatform(s)
+				Expect(err).ShouldNot(HaveOccurred())
+				platforms = append(platforms, platform)
+			}
+			analyzer.SetPlatforms(platforms)
+			analyzer.SetStaleNosec(true)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G104")).Builders())
+			err = analyzer.Process(buildTags, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _ := analyzer.Report()
+			Expect(metrics.NumFound).To(Equal(1))
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].RuleID).To(Equal(gosec.StaleNosecRuleID))
+			Expect(issues[0].Line).To(Equal("5"))
+			Expect(issues[0].Platforms).To(Equal([]string{"linux/amd64", "windows/amd64"}))
+		})
+
+		It("should mark, downgrade or skip the issues of the generated files", func() {
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\n"+testutils.SampleCodeG401[0].Code[0])
+			pkg.AddFile("sha1.go", `
+				package main
+				// Code generated by hand. DO NOT EDIT.
+				import "crypto/sha1"
+				func hash(data []byte) [sha1.Size]byte {
+					return sha1.Sum(data)
+				}`)
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			check := func(mode string) ([]*gosec.Issue, *gosec.Metrics) {
+				config := gosec.NewConfig()
+				if mode != "" {
+					config.SetGlobal(gosec.GeneratedCode, mode)
+				}
+				customAnalyzer := gosec.NewAnalyzer(config, tests, logger)
+				customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+				err := customAnalyzer.Process(buildTags, pkg.Path)
+				Expect(err).ShouldNot(HaveOccurred())
+				issues, metrics, _ := customAnalyzer.Report()
+				return issues, metrics
+			}
+
+			issues, metrics := check("")
+			Expect(metrics.NumFiles).To(Equal(2))
+			Expect(metrics.NumGenerated).To(Equal(1))
+			Expect(issues).To(HaveLen(2))
+			for _, issue := range issues {
+				Expect(issue.Generated).To(Equal(strings.HasSuffix(issue.File, "md5.go")))
+				Expect(issue.Severity).To(Equal(gosec.Medium))
+			}
+
+			issues, _ = check(gosec.GeneratedDowngrade)
+			Expect(issues).To(HaveLen(2))
+			for _, issue := range issues {
+				if issue.Generated {
+					Expect(issue.Severity).To(Equal(gosec.Low))
+				} else {
+					Expect(issue.Severity).To(Equal(gosec.Medium))
+				}
+			}
+
+			issues, metrics = check(gosec.GeneratedSkip)
+			Expect(metrics.NumFiles).To(Equal(2))
+			Expect(metrics.NumGenerated).To(Equal(1))
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].File).To(HaveSuffix("sha1.go"))
+			Expect(issues[0].Generated).To(BeFalse())
+		})
+
+		It("should check the unsaved files of the overlay with their package", func() {
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("main.go", `
+				package main
+				func main() {
+					println(hash(nil)[0])
+				}`)
+			pkg.AddFile("hash.go", `
+				package main
+				func hash(data []byte) []byte {
+					return data
+				}`)
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			unsaved := filepath.Join(pkg.Path, "hash.go")
+			added := filepath.Join(pkg.Path, "sha1.go")
+			analyzer.SetOverlay(map[string][]byte{
+				unsaved: []byte("package main\n\nimport \"crypto/md5\"\n\nfunc hash(data []byte) [md5.Size]byte {\n\treturn md5.Sum(data)\n}\n"),
+				added:   []byte("package main\n\nimport \"crypto/sha1\"\n\nvar digest = sha1.Sum(nil)\n"),
+			})
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, errors := analyzer.Report()
+			Expect(errors).To(BeEmpty())
+			Expect(metrics.NumFiles).To(Equal(3))
+			Expect(issues).To(HaveLen(2))
+			files := []string{issues[0].File, issues[1].File}
+			Expect(files).To(ConsistOf(unsaved, added))
+			for _, issue := range issues {
+				if issue.File == unsaved {
+					Expect(issue.Code).To(ContainSubstring("6: \treturn md5.Sum(data)"))
+				}
+			}
+		})
+
+		It("should report the same results regardless of the concurrency", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			var paths []string
+			for i := 0; i < 4; i++ {
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("md5.go", source)
+				err := pkg.Build()
+				Expect(err).ShouldNot(HaveOccurred())
+				paths = append(paths, pkg.Path)
+			}
+
+			analyzer.LoadRules(rules.Generate().Builders())
+			err := analyzer.Process(buildTags, paths...)
+			Expect(err).ShouldNot(HaveOccurred())
+			serialIssues, serialMetrics, _ := analyzer.Report()
+
+			parallel := gosec.NewAnalyzer(nil, tests, logger)
+			parallel.SetConcurrency(4)
+			parallel.LoadRules(rules.Generate().Builders())
+			err = parallel.Process(buildTags, paths...)
+			Expect(err).ShouldNot(HaveOccurred())
+			parallelIssues, parallelMetrics, _ := parallel.Report()
+
+			Expect(parallelMetrics).To(Equal(serialMetrics))
+			Expect(parallelIssues).To(HaveLen(len(serialIssues)))
+			for i, issue := range parallelIssues {
+				Expect(issue.FileLocation()).To(Equal(serialIssues[i].FileLocation()))
+				Expect(issue.RuleID).To(Equal(serialIssues[i].RuleID))
+			}
+		})
+
+		It("should reuse the cached results of the unchanged packages", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", source)
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dir, err := ioutil.TempDir("", "cache")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			cache, err := gosec.NewCache(dir, "test")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			analyzer.SetCache(cache)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _ := analyzer.Report()
+			Expect(issues).To(HaveLen(sample.Errors))
+
+			cachedLogger, logs := testutils.NewLogger()
+			cached := gosec.NewAnalyzer(nil, tests, cachedLogger)
+			cached.SetCache(cache)
+			cached.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = cached.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			cachedIssues, cachedMetrics, _ := cached.Report()
+			Expect(logs.String()).To(ContainSubstring("Cached directory:"))
+			Expect(logs.String()).ShouldNot(ContainSubstring("Checking package:"))
+			Expect(cachedMetrics).To(Equal(metrics))
+			Expect(cachedIssues).To(HaveLen(len(issues)))
+			for i, issue := range cachedIssues {
+				Expect(*issue).To(Equal(*issues[i]))
+			}
+
+			err = ioutil.WriteFile(filepath.Join(pkg.Path, "md5.go"), []byte(source+"\nfunc unused() {}\n"), 0600)
+			Expect(err).ShouldNot(HaveOccurred())
+			changedLogger, logs := testutils.NewLogger()
+			changed := gosec.NewAnalyzer(nil, tests, changedLogger)
+			changed.SetCache(cache)
+			changed.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			err = changed.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(logs.String()).To(ContainSubstring("Checking package:"))
+		})
+
+		It("should check again the packages when an input of the cache changes", func() {
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", testutils.SampleCodeG401[0].Code[0])
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dir, err := ioutil.TempDir("", "cache")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			process := func(input string) string {
+				cache, err := gosec.NewCache(dir, "test")
+				Expect(err).ShouldNot(HaveOccurred())
+				cache.AddInput("rules-file", []byte(input))
+				logger, logs := testutils.NewLogger()
+				analyzer := gosec.NewAnalyzer(nil, tests, logger)
+				analyzer.SetCache(cache)
+				analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+				Expect(analyzer.Process(buildTags, pkg.Path)).Should(Succeed())
+				return logs.String()
+			}
+			Expect(process("rules: []")).To(ContainSubstring("Checking package:"))
+			Expect(process("rules: []")).ShouldNot(ContainSubstring("Checking package:"))
+			Expect(process("rules: [{id: C101}]")).To(ContainSubstring("Checking package:"))
+		})
+
 		It("should find errors when nosec is not in use", func() {
 			sample := testutils.SampleCodeG401[0]
 			source := sample.Code[0]
@@ -211,6 +682,253 @@ var _ = Describe("Analyzer", func() {
 			Expect(nosecIssues).Should(BeEmpty())
 		})
 
+		It("should not take the words looking like rule IDs for rule IDs in nosec comments", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec we keep SHA256 elsewhere, see RFC7231", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			nosecIssues, _, _ := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+		})
+
+		It("should track the issues suppressed by nosec comments along with their justification", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			analyzer.SetTrackSuppressions(true)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec G401 -- checksum only, see G101", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _ := analyzer.Report()
+			Expect(issues).Should(BeEmpty())
+			Expect(metrics.NumFound).Should(Equal(0))
+			suppressed := analyzer.Suppressed()
+			Expect(suppressed).Should(HaveLen(sample.Errors))
+			Expect(suppressed[0].RuleID).Should(Equal("G401"))
+			Expect(suppressed[0].Suppressions).Should(Equal([]gosec.SuppressionInfo{
+				{Kind: gosec.InSource, Justification: "checksum only, see G101"},
+			}))
+		})
+
+		It("should track the issues suppressed by nosec comments for all the rules", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			analyzer.SetTrackSuppressions(true)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, errors := analyzer.Report()
+			Expect(issues).Should(BeEmpty())
+			Expect(errors).Should(BeEmpty())
+			suppressed := analyzer.Suppressed()
+			Expect(suppressed).Should(HaveLen(sample.Errors))
+			Expect(suppressed[0].Suppressions).Should(Equal([]gosec.SuppressionInfo{{Kind: gosec.InSource}}))
+		})
+
+		It("should report the nosec comments which don't suppress any issue", func() {
+			source := testutils.SampleCodeG401[0].Code[0]
+			analyzer.SetStaleNosec(true)
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401", "G304")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec G401 G304 G999 -- checksum only", 1)
+			nosecSource = strings.Replace(nosecSource, `fmt.Printf("%x", h.Sum(nil))`, `fmt.Printf("%x", h.Sum(nil)) // #nosec`, 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _ := analyzer.Report()
+			// the nosec directive for all the rules might suppress the issue of a rule
+			// which is not loaded
+			Expect(issues).Should(HaveLen(1))
+			Expect(metrics.NumFound).Should(Equal(1))
+			Expect(issues[0].RuleID).Should(Equal(gosec.StaleNosecRuleID))
+			Expect(issues[0].What).Should(ContainSubstring("any G304 issue"))
+			Expect(issues[0].Line).Should(Equal("25"))
+			Expect(analyzer.Suppressed()).Should(BeEmpty())
+		})
+
+		It("should report the nosec comments for all the rules which don't suppress any issue of all the rules", func() {
+			analyzer.SetStaleNosec(true)
+			analyzer.LoadRules(rules.Generate().Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecPackage.AddFile("main.go", `
+				package main
+				func main() {
+					println("done") // #nosec
+				}`)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].RuleID).Should(Equal(gosec.StaleNosecRuleID))
+			Expect(issues[0].What).Should(ContainSubstring("any issue"))
+			Expect(issues[0].Line).Should(Equal("4"))
+		})
+
+		It("should give distinct fingerprints to the identical issues of a function", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", `
+				package main
+				import "crypto/md5"
+				func main() {
+					println(md5.New())
+					println(md5.New())
+				}`)
+			Expect(pkg.Build()).Should(Succeed())
+			Expect(analyzer.Process(buildTags, pkg.Path)).Should(Succeed())
+			issues, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(2))
+			Expect(issues[0].Fingerprint).ShouldNot(Equal(issues[1].Fingerprint))
+		})
+
+		It("should give the same fingerprints with and without tracking the suppressions", func() {
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", `
+				package main
+				import "crypto/md5"
+				func main() {
+					println(md5.New()) // #nosec G401
+					println(md5.New())
+				}`)
+			Expect(pkg.Build()).Should(Succeed())
+			var fingerprints []string
+			for _, track := range []bool{false, true} {
+				analyzer.Reset()
+				analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+				analyzer.SetTrackSuppressions(track)
+				Expect(analyzer.Process(buildTags, pkg.Path)).Should(Succeed())
+				issues, _, _ := analyzer.Report()
+				Expect(issues).Should(HaveLen(1))
+				fingerprints = append(fingerprints, issues[0].Fingerprint)
+			}
+			Expect(fingerprints[0]).Should(Equal(fingerprints[1]))
+		})
+
+		It("should profile the rules and the load of the packages when enabled", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+			analyzer.SetProfileRules(true)
+
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", testutils.SampleCodeG401[0].Code[0])
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, metrics, _ := analyzer.Report()
+			Expect(metrics.Profile).ShouldNot(BeNil())
+			Expect(metrics.Profile.Rules).Should(HaveKey("G401"))
+			Expect(metrics.Profile.Rules["G401"].Calls).Should(BeNumerically(">", 0))
+			Expect(metrics.Profile.Rules["G401"].Issues).Should(Equal(testutils.SampleCodeG401[0].Errors))
+			Expect(metrics.Profile.Rules["G401"].Max).Should(BeNumerically("<=", metrics.Profile.Rules["G401"].Total))
+			Expect(metrics.Profile.Packages).Should(HaveLen(1))
+			Expect(metrics.Profile.Packages[0].Path).Should(Equal(pkg.Path))
+			Expect(metrics.Profile.Packages[0].Load).Should(BeNumerically(">", 0))
+		})
+
+		It("should not profile the rules by default", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", testutils.SampleCodeG401[0].Code[0])
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, metrics, _ := analyzer.Report()
+			Expect(metrics.Profile).Should(BeNil())
+		})
+
+		It("should report the rules which fail and keep scanning with the other rules", func() {
+			builders := rules.Generate(rules.NewRuleFilter(false, "G401")).Builders()
+			builders["MOCK"] = func(id string, c gosec.Config) (gosec.Rule, []ast.Node) {
+				return &mockrule{callback: func(n ast.Node, ctx *gosec.Context) bool {
+					panic("boom")
+				}}, []ast.Node{(*ast.CallExpr)(nil)}
+			}
+			builders["BROKEN"] = func(id string, c gosec.Config) (gosec.Rule, []ast.Node) {
+				panic("invalid configuration")
+			}
+			analyzer.LoadRules(builders)
+
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", testutils.SampleCodeG401[0].Code[0])
+			err := pkg.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(testutils.SampleCodeG401[0].Errors))
+
+			failures := analyzer.RuleFailures()
+			Expect(failures).ShouldNot(BeEmpty())
+			Expect(failures[0].RuleID).Should(Equal("BROKEN"))
+			Expect(failures[0].Err).Should(Equal("panic: invalid configuration"))
+			Expect(failures[1].RuleID).Should(Equal("MOCK"))
+			Expect(failures[1].Err).Should(Equal("panic: boom"))
+			Expect(failures[1].File).Should(HaveSuffix("md5.go"))
+			Expect(failures[1].Line).Should(BeNumerically(">", 0))
+			Expect(failures[1].Stack).Should(ContainSubstring("mockrule"))
+		})
+
+		It("should report an error when a nosec comment has no justification and justifications are required", func() {
+			sample := testutils.SampleCodeG401[0]
+			source := sample.Code[0]
+			config := gosec.NewConfig()
+			config.SetGlobal(gosec.NoSecJustification, "enabled")
+			customAnalyzer := gosec.NewAnalyzer(config, tests, logger)
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "G401")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec G401", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			err := nosecPackage.Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			err = customAnalyzer.Process(buildTags, nosecPackage.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, errors := customAnalyzer.Report()
+			Expect(issues).Should(BeEmpty())
+			Expect(errors).Should(HaveLen(1))
+			for _, ferr := range errors {
+				Expect(ferr).Should(HaveLen(1))
+				Expect(ferr[0].Err).Should(ContainSubstring("without justification"))
+			}
+		})
+
 		It("should pass the build tags", func() {
 			sample := testutils.SampleCodeBuildTag[0]
 			source := sample.Code[0]
@@ -517,3 +1235,12 @@ var _ = Describe("Analyzer", func() {
 		})
 	})
 })
+
+// writeFiles writes the files, given by slash separated path, in the root directory
+func writeFiles(root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).Should(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).Should(Succeed())
+	}
+}
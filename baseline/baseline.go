@@ -0,0 +1,158 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package baseline records the known issues of a code base in a file, in order
+// to report only the new issues in the next scans.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/securego/gosec/v2"
+)
+
+// Entry identifies a known issue by the fingerprint of the issue. The line of the
+// issue is not recorded, so that the entry still matches after unrelated edits in
+// the file.
+type Entry struct {
+	RuleID      string `json:"rule_id"`
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+	What        string `json:"details"`
+}
+
+// key identifies the entries which match the same issues
+func (e Entry) key() string {
+	return strings.Join([]string{e.RuleID, e.File, e.Fingerprint}, "\x00")
+}
+
+// Baseline holds the known issues
+type Baseline struct {
+	Issues []Entry `json:"issues"`
+}
+
+// Load reads a baseline file
+func Load(path string) (*Baseline, error) {
+	data, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("reading baseline %q: %v", path, err)
+	}
+	return &b, nil
+}
+
+// Save writes the baseline into a file
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// New creates a baseline from the issues. The file paths are recorded relatively
+// to the root directory, which is usually the directory of the baseline file.
+func New(root string, issues []*gosec.Issue) *Baseline {
+	b := &Baseline{Issues: Entries(root, issues)}
+	sort.Slice(b.Issues, func(i, j int) bool {
+		return b.Issues[i].key() < b.Issues[j].key()
+	})
+	return b
+}
+
+// Entries returns the entries of the issues, in the order of the issues. The file
+// paths are recorded relatively to the root directory.
+func Entries(root string, issues []*gosec.Issue) []Entry {
+	entries := make([]Entry, 0, len(issues))
+	for _, issue := range issues {
+		entries = append(entries, newEntry(root, issue))
+	}
+	return entries
+}
+
+// Filter returns the issues which are not recorded in the baseline, along with
+// the number of new, unchanged and fixed issues. An entry of the baseline matches
+// a single issue, hence duplicated issues are new when they exceed the count of
+// the recorded ones.
+func (b *Baseline) Filter(root string, issues []*gosec.Issue) ([]*gosec.Issue, *gosec.BaselineMetrics) {
+	known := make(map[string]int, len(b.Issues))
+	for _, entry := range b.Issues {
+		known[entry.key()]++
+	}
+
+	metrics := &gosec.BaselineMetrics{}
+	result := make([]*gosec.Issue, 0, len(issues))
+	for _, issue := range issues {
+		key := newEntry(root, issue).key()
+		if known[key] > 0 {
+			known[key]--
+			metrics.Unchanged++
+			continue
+		}
+		metrics.New++
+		result = append(result, issue)
+	}
+	for _, count := range known {
+		metrics.Fixed += count
+	}
+	return result, metrics
+}
+
+func newEntry(root string, issue *gosec.Issue) Entry {
+	return Entry{
+		RuleID:      issue.RuleID,
+		File:        relative(root, issue.File),
+		Fingerprint: fingerprint(issue),
+		What:        issue.What,
+	}
+}
+
+// relative converts the path of a file into a slash separated path relative to
+// the root directory
+func relative(root string, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(abs)
+}
+
+// fingerprint returns the fingerprint of the issue, or hashes the code snippet of
+// the issue when it has none, e.g. when it is read from an older report. The line
+// numbers and the whitespaces are removed from the snippet.
+func fingerprint(issue *gosec.Issue) string {
+	if issue.Fingerprint != "" {
+		return issue.Fingerprint
+	}
+	var code strings.Builder
+	for _, line := range strings.Split(issue.Code, "\n") {
+		if i := strings.Index(line, ": "); i >= 0 {
+			line = line[i+2:]
+		}
+		code.WriteString(strings.Join(strings.Fields(line), ""))
+	}
+	sum := sha256.Sum256([]byte(code.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package baseline_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/baseline"
+)
+
+var _ = Describe("Baseline", func() {
+	var (
+		root string
+		file string
+	)
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "baseline")
+		Expect(err).ShouldNot(HaveOccurred())
+		file = filepath.Join(root, "cmd", "main.go")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	issue := func(ruleID, line, fingerprint string) *gosec.Issue {
+		return &gosec.Issue{RuleID: ruleID, File: file, Line: line, What: "details", Fingerprint: fingerprint}
+	}
+
+	It("should record the rule, the relative file and the fingerprint", func() {
+		b := baseline.New(root, []*gosec.Issue{issue("G401", "15", "b2"), issue("G204", "11", "a1")})
+		Expect(b.Issues).To(Equal([]baseline.Entry{
+			{RuleID: "G204", File: "cmd/main.go", Fingerprint: "a1", What: "details"},
+			{RuleID: "G401", File: "cmd/main.go", Fingerprint: "b2", What: "details"},
+		}))
+	})
+
+	It("should hash the code of the issues without fingerprint", func() {
+		reformatted := issue("G401", "16", "")
+		reformatted.Code = "16: println(md5.New( ))\n"
+		b := baseline.New(root, []*gosec.Issue{{RuleID: "G401", File: file, Code: "15: println(md5.New())\n"}})
+		issues, metrics := b.Filter(root, []*gosec.Issue{reformatted})
+		Expect(issues).To(BeEmpty())
+		Expect(metrics.Unchanged).To(Equal(1))
+	})
+
+	It("should be saved and loaded", func() {
+		b := baseline.New(root, []*gosec.Issue{issue("G204", "11", "a1")})
+		path := filepath.Join(root, "gosec-baseline.json")
+		Expect(b.Save(path)).Should(Succeed())
+		loaded, err := baseline.Load(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(loaded).To(Equal(b))
+	})
+
+	It("should match the known issues after unrelated edits", func() {
+		b := baseline.New(root, []*gosec.Issue{issue("G204", "11", "a1"), issue("G401", "15", "b2")})
+
+		current := []*gosec.Issue{issue("G204", "12", "a1"), issue("G401", "16", "b2"), issue("G401", "17", "b3")}
+		issues, metrics := b.Filter(root, current)
+		Expect(issues).To(Equal([]*gosec.Issue{current[2]}))
+		Expect(*metrics).To(Equal(gosec.BaselineMetrics{New: 1, Unchanged: 2, Fixed: 0}))
+	})
+
+	It("should count the fixed issues", func() {
+		b := baseline.New(root, []*gosec.Issue{issue("G204", "11", "a1"), issue("G401", "15", "b2")})
+		issues, metrics := b.Filter(root, []*gosec.Issue{issue("G401", "15", "b2")})
+		Expect(issues).To(BeEmpty())
+		Expect(*metrics).To(Equal(gosec.BaselineMetrics{New: 0, Unchanged: 1, Fixed: 1}))
+	})
+})
@@ -0,0 +1,261 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cacheLoadMode controls the details loaded when computing the cache key of a
+// package. The export data of the dependencies is produced by the go build cache,
+// so this is much cheaper than type checking the package.
+const cacheLoadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedDeps |
+	packages.NeedExportsFile
+
+// Cache stores on disk the results of the analysis of each package, keyed on the
+// content of the package, the export data of its dependencies, the gosec version,
+// the rules and the configuration. The packages whose key didn't change are not
+// checked again.
+type Cache struct {
+	dir     string
+	version string
+	inputs  map[string]string
+}
+
+// cacheEntry holds the results of a package stored in the cache
+type cacheEntry struct {
+	Issues     []*Issue           `json:"issues"`
+	Suppressed []*Issue           `json:"suppressed"`
+	Failures   []*RuleFailure     `json:"failures"`
+	Stats      *Metrics           `json:"stats"`
+	Errors     map[string][]Error `json:"errors"`
+	Files      []string           `json:"files"`
+}
+
+// DefaultCacheDir returns the gosec directory in the user cache directory
+// (e.g. $XDG_CACHE_HOME/gosec)
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gosec"), nil
+}
+
+// NewCache creates a cache in the given directory for the given gosec version
+func NewCache(dir string, version string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating cache directory %q: %v", dir, err)
+	}
+	return &Cache{dir: dir, version: version, inputs: make(map[string]string)}, nil
+}
+
+// AddInput adds to the key of every package an input which the results depend on,
+// but which is not known by the analyzer, e.g. the definition of a rule, the
+// content of the file declaring the custom rules or the identity of a plugin. It
+// must be called before the scan.
+func (c *Cache) AddInput(name string, content []byte) {
+	sum := sha256.Sum256(content)
+	c.inputs[name] = hex.EncodeToString(sum[:])
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// get retrieves the results of a package from the cache
+func (c *Cache) get(key string) (*packageResult, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Stats == nil {
+		return nil, false
+	}
+	for _, issue := range append(entry.Issues, entry.Suppressed...) {
+		issue.Cwe = GetCweByRule(issue.RuleID)
+	}
+	if entry.Errors == nil {
+		entry.Errors = make(map[string][]Error)
+	}
+	return &packageResult{
+		issues:     entry.Issues,
+		suppressed: entry.Suppressed,
+		failures:   entry.Failures,
+		stats:      entry.Stats,
+		errors:     entry.Errors,
+		files:      entry.Files,
+	}, true
+}
+
+// put stores the results of a package in the cache. The entry is written to a
+// temporary file first, so that concurrent scans never read a partial entry.
+func (c *Cache) put(key string, result *packageResult) error {
+	// the profile is not stored, since the cached packages are not checked again
+	stats := *result.stats
+	stats.Profile = nil
+	data, err := json.Marshal(&cacheEntry{
+		Issues:     result.issues,
+		Suppressed: result.suppressed,
+		Failures:   result.failures,
+		Stats:      &stats,
+		Errors:     result.errors,
+		Files:      result.files,
+	})
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()           // #nosec G104
+		os.Remove(tmp.Name()) // #nosec G104
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) // #nosec G104
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cacheKey computes the key of a package path in the cache. The key changes when
+// any of the analyzed files, the export data of the imported packages, the gosec
+// version, the rules, the inputs of the cache, the configuration or the load
+// options change.
+func (gosec *Analyzer) cacheKey(buildTags []string, pkgPath string) (string, error) {
+	files, err := gosec.packageFiles(pkgPath, buildTags)
+	if err != nil {
+		return "", err
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:    cacheLoadMode,
+		Tests:   gosec.tests,
+		Env:     gosec.loadEnv(),
+		Overlay: gosec.overlay,
+		Context: gosec.ctx,
+	}, files...)
+	if err != nil {
+		return "", err
+	}
+	return gosec.hashPackages(buildTags, pkgs)
+}
+
+// hashPackages computes the cache key of the packages loaded from a package path
+func (gosec *Analyzer) hashPackages(buildTags []string, pkgs []*packages.Package) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version %s\n", gosec.cache.version)
+	fmt.Fprintf(hash, "tests %t nosec %t suppressions %t stale %t tags %s\n",
+		gosec.tests, gosec.ignoreNosec, gosec.trackSuppressions, gosec.staleNosec, strings.Join(buildTags, ","))
+	if gosec.platform != nil {
+		fmt.Fprintf(hash, "platform %s\n", gosec.platform)
+	}
+
+	ids := make([]string, 0, len(gosec.builders))
+	for id := range gosec.builders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fmt.Fprintf(hash, "rules %s\n", strings.Join(ids, ","))
+
+	inputs := make([]string, 0, len(gosec.cache.inputs))
+	for name := range gosec.cache.inputs {
+		inputs = append(inputs, name)
+	}
+	sort.Strings(inputs)
+	for _, name := range inputs {
+		fmt.Fprintf(hash, "input %s %s\n", name, gosec.cache.inputs[name])
+	}
+
+	config, err := json.Marshal(gosec.config)
+	if err != nil {
+		return "", fmt.Errorf("encoding config: %v", err)
+	}
+	fmt.Fprintf(hash, "config %s\n", config)
+
+	pkgs = append([]*packages.Package{}, pkgs...)
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].ID < pkgs[j].ID })
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return "", fmt.Errorf("loading package %q: %v", pkg.ID, pkg.Errors[0])
+		}
+		fmt.Fprintf(hash, "package %s\n", pkg.ID)
+		for _, file := range append(pkg.CompiledGoFiles, pkg.OtherFiles...) {
+			if err := gosec.hashFile(hash, file); err != nil {
+				return "", err
+			}
+		}
+
+		imports := make([]string, 0, len(pkg.Imports))
+		for path := range pkg.Imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		for _, path := range imports {
+			imp := pkg.Imports[path]
+			fmt.Fprintf(hash, "import %s\n", imp.ID)
+			if imp.ExportFile == "" {
+				continue
+			}
+			if err := gosec.hashFile(hash, imp.ExportFile); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashFile writes the name and the content of a file into the hash, reading the
+// content from the overlay when the file is not saved
+func (gosec *Analyzer) hashFile(hash io.Writer, name string) error {
+	if content, ok := gosec.overlay[name]; ok {
+		fmt.Fprintf(hash, "file %s\n", name)
+		_, err := hash.Write(content)
+		return err
+	}
+	file, err := os.Open(name) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer file.Close() // #nosec G307
+	fmt.Fprintf(hash, "file %s\n", name)
+	_, err = io.Copy(hash, file)
+	return err
+}
@@ -83,9 +83,7 @@ func (c CallList) ContainsPkgCallExpr(n ast.Node, ctx *Context, stripVendor bool
 		return nil
 	}
 	if stripVendor {
-		if vendorIdx := strings.Index(path, vendorPath); vendorIdx >= 0 {
-			path = path[vendorIdx+len(vendorPath):]
-		}
+		path = StripVendor(path)
 	}
 	if !c.Contains(path, ident) {
 		return nil
@@ -0,0 +1,13 @@
+package cli
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestGosec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gosec Command Suite")
+}
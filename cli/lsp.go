@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/lsp"
+	"github.com/securego/gosec/v2/rules"
+)
+
+// serveLSP serves an editor over stdin and stdout with the language server
+// protocol, until the editor exits
+func serveLSP(config gosec.Config, ruleList rules.RuleList, logger *log.Logger) error {
+	server := lsp.NewServer(config, ruleList, *flagScanTests, logger)
+	server.SetVersion(Version)
+	if *flagBuildTags != "" {
+		server.SetBuildTags(strings.Split(*flagBuildTags, ","))
+	}
+	return server.Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,692 @@
+// (c) Copyright 2016 Hewlett Packard Enterprise Development LP
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cli implements the gosec command line. The custom binaries, e.g. with
+// additional rules registered by their packages, run the same command line by
+// calling Main.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/baseline"
+	"github.com/securego/gosec/v2/diff"
+	"github.com/securego/gosec/v2/plugin"
+	"github.com/securego/gosec/v2/report"
+	"github.com/securego/gosec/v2/rules"
+)
+
+const (
+	// defaultBaseline is the baseline file written when none is provided
+	defaultBaseline = "gosec-baseline.json"
+
+	// exitRuleFailure is the exit code when a rule failed on the scanned code
+	exitRuleFailure = 3
+
+	usageText = `
+gosec - Golang security checker
+
+gosec analyzes Go source code to look for common programming mistakes that
+can lead to security problems.
+
+VERSION: %s
+GIT TAG: %s
+BUILD DATE: %s
+
+USAGE:
+
+	# Check a single package
+	$ gosec $GOPATH/src/github.com/example/project
+
+	# Check all packages under the current directory and save results in
+	# json format.
+	$ gosec -fmt=json -out=results.json ./...
+
+	# Run a specific set of rules (by default all rules will be run):
+	$ gosec -include=G101,G203,G401  ./...
+
+	# Run all rules except the provided
+	$ gosec -exclude=G101 $GOPATH/src/github.com/example/project/...
+
+	# Check again the packages which change, and print the added or
+	# resolved issues
+	$ gosec -watch ./...
+
+	# Serve the editors with the language server protocol over stdio
+	$ gosec lsp
+
+`
+)
+
+type arrayFlags []string
+
+func (a *arrayFlags) String() string {
+	return strings.Join(*a, " ")
+}
+
+func (a *arrayFlags) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+var (
+	// #nosec flag
+	flagIgnoreNoSec = flag.Bool("nosec", false, "Ignores #nosec comments when set")
+
+	// format output
+	flagFormat = flag.String("fmt", "text", "Set output format. Valid options are: json, yaml, csv, junit-xml, html, sonarqube, golint, sarif or text")
+
+	// #nosec alternative tag
+	flagAlternativeNoSec = flag.String("nosec-tag", "", "Set an alternative string for #nosec. Some examples: #dontanalyze, #falsepositive")
+
+	// output file
+	flagOutput = flag.String("out", "", "Set output file for results")
+
+	// config file
+	flagConfig = flag.String("conf", "", "Path to optional config file")
+
+	// quiet
+	flagQuiet = flag.Bool("quiet", false, "Only show output when errors are found")
+
+	// rules to explicitly include
+	flagRulesInclude = flag.String("include", "", "Comma separated list of rules IDs to include. (see rule list)")
+
+	// rules to explicitly exclude
+	flagRulesExclude = flag.String("exclude", "", "Comma separated list of rules IDs to exclude. (see rule list)")
+
+	// log to file or stderr
+	flagLogfile = flag.String("log", "", "Log messages to file rather than stderr")
+
+	// sort the issues by severity
+	flagSortIssues = flag.Bool("sort", true, "Sort issues by severity")
+
+	// go build tags
+	flagBuildTags = flag.String("tags", "", "Comma separated list of build tags")
+
+	// fail by severity
+	flagSeverity = flag.String("severity", "low", "Filter out the issues with a lower severity than the given value. Valid options are: low, medium, high")
+
+	// fail by confidence
+	flagConfidence = flag.String("confidence", "low", "Filter out the issues with a lower confidence than the given value. Valid options are: low, medium, high")
+
+	// do not fail
+	flagNoFail = flag.Bool("no-fail", false, "Do not fail the scanning, even if issues were found")
+
+	// scan tests files
+	flagScanTests = flag.Bool("tests", false, "Scan tests files")
+
+	// print version and quit with exit code 0
+	flagVersion = flag.Bool("version", false, "Print version and quit with exit code 0")
+
+	// stdout the results as well as write it in the output file
+	flagStdOut = flag.Bool("stdout", false, "Stdout the results as well as write it in the output file")
+
+	// print the text report with color, this is enabled by default
+	flagColor = flag.Bool("color", true, "Prints the text format report with colorization when it goes in the stdout")
+
+	// overrides the output format when stdout the results while saving them in the output file
+	flagVerbose = flag.String("verbose", "", "Overrides the output format when stdout the results while saving them in the output file.\nValid options are: json, yaml, csv, junit-xml, html, sonarqube, golint, sarif or text")
+
+	// concurrency value
+	flagConcurrency = flag.Int("concurrency", runtime.NumCPU(), "Number of packages analyzed concurrently")
+
+	// enable the taint analysis
+	flagTaint = flag.Bool("taint", false, "Track the untrusted input through the code to reduce the false positives of the injection rules")
+
+	// cache the results of the unchanged packages
+	flagCache = flag.Bool("cache", false, "Reuse the results of the packages which did not change since the previous scan")
+
+	// cache directory
+	flagCacheDir = flag.String("cache-dir", "", "Directory of the cache (default the gosec directory in the user cache directory)")
+
+	// report only the issues in the lines changed since a git revision
+	flagDiff = flag.String("diff", "", "Report only the issues in the lines added or changed since the given git revision")
+
+	// report only the issues which are not recorded in the baseline file
+	flagBaseline = flag.String("baseline", "", "Report only the issues which are not recorded in the given baseline file")
+
+	// record the current issues in the baseline file
+	flagWriteBaseline = flag.Bool("write-baseline", false, "Record the current issues in the baseline file (default "+defaultBaseline+")")
+
+	// report the issues suppressed by #nosec
+	flagTrackSuppressions = flag.Bool("track-suppressions", false, "Report the issues suppressed by #nosec along with their justification")
+
+	// report the #nosec which don't suppress any issue
+	flagStaleNosec = flag.Bool("stale-nosec", false, "Report the #nosec annotations, or the rule IDs listed in them, which don't suppress any issue")
+
+	// fail the scan when a #nosec has no justification
+	flagRequireJustification = flag.Bool("require-justification", false, "Report an error for each #nosec without a justification (e.g. #nosec G304 -- embedded file)")
+
+	// profile the rules and the load of the packages
+	flagProfileRules = flag.Bool("profile-rules", false, "Print the number of calls and the time spent in each rule, and the slowest loads of the packages")
+
+	// platforms for which the packages are checked
+	flagPlatforms = flag.String("platforms", "", "Comma separated list of platforms for which the packages are checked, in the goos/goarch[:tag1+tag2] format (e.g. linux/amd64,windows/amd64)")
+
+	// handling of the generated files
+	flagGenerated = flag.String("generated", "", "Set how the issues of the generated files are handled. Valid options are: report, downgrade (to a low severity) or skip (default report)")
+
+	// read the content of a file from stdin
+	flagStdin = flag.Bool("stdin", false, "Read the content of the file given by -stdin-filename from stdin, and report only its issues")
+
+	// name of the file read from stdin
+	flagStdinFilename = flag.String("stdin-filename", "", "Path of the file read from stdin, which is checked with the other files of its package")
+
+	// check again the packages when their files change
+	flagWatch = flag.Bool("watch", false, "Keep running and check again the packages whose Go files change, printing only the issues added or resolved")
+
+	// interval between the checks of the changed files
+	flagWatchInterval = flag.Duration("watch-interval", time.Second, "Interval between the checks of the changed files in watch mode")
+
+	// declarative rules checking the calls
+	flagRulesFile = flag.String("rules-file", "", "Load the custom rules declared in the given YAML or JSON file")
+
+	// exlude the folders from scan
+	flagDirsExclude arrayFlags
+
+	logger *log.Logger
+)
+
+// #nosec
+func usage() {
+	usageText := fmt.Sprintf(usageText, Version, GitTag, BuildDate)
+	fmt.Fprintln(os.Stderr, usageText)
+	fmt.Fprint(os.Stderr, "OPTIONS:\n\n")
+	flag.PrintDefaults()
+	fmt.Fprint(os.Stderr, "\n\nRULES:\n\n")
+
+	// sorted rule list for ease of reading
+	rl := rules.Generate()
+	keys := make([]string, 0, len(rl))
+	for key := range rl {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := rl[k]
+		tags := ""
+		if len(v.Tags) > 0 {
+			tags = fmt.Sprintf(" [%s]", strings.Join(v.Tags, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "\t%s: %s%s\n", k, v.Description, tags)
+	}
+	fmt.Fprint(os.Stderr, "\n")
+}
+
+func loadConfig(configFile string) (gosec.Config, error) {
+	config := gosec.NewConfig()
+	if configFile != "" {
+		// #nosec
+		file, err := os.Open(configFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close() // #nosec G307
+		if _, err := config.ReadFrom(file); err != nil {
+			return nil, err
+		}
+	}
+	if *flagIgnoreNoSec {
+		config.SetGlobal(gosec.Nosec, "true")
+	}
+	if *flagAlternativeNoSec != "" {
+		config.SetGlobal(gosec.NoSecAlternative, *flagAlternativeNoSec)
+	}
+	if *flagTaint {
+		config.SetGlobal(gosec.TaintAnalysis, "enabled")
+	}
+	if *flagRequireJustification {
+		config.SetGlobal(gosec.NoSecJustification, "enabled")
+	}
+	if *flagGenerated != "" {
+		config.SetGlobal(gosec.GeneratedCode, *flagGenerated)
+	}
+	if mode, err := config.GetGlobal(gosec.GeneratedCode); err == nil {
+		if err := gosec.ValidateGeneratedMode(mode); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+func loadCache(cacheDir string) (*gosec.Cache, error) {
+	if cacheDir == "" {
+		dir, err := gosec.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheDir = dir
+	}
+	return gosec.NewCache(cacheDir, Version)
+}
+
+// addCacheInputs adds to the cache the definitions of the rules, the content of
+// the file declaring the custom rules and the identity of the plugins, so that the
+// packages are checked again when any of them change
+func addCacheInputs(cache *gosec.Cache, definitions rules.RuleList, rulesFile string, plugins []*plugin.Plugin) error {
+	for id, def := range definitions {
+		definition := fmt.Sprintf("%s\n%v\n%v\n%s\n%s", def.Description, def.Severity, def.Confidence, def.CWE, strings.Join(def.Tags, ","))
+		cache.AddInput("rule "+id, []byte(definition))
+	}
+	if rulesFile != "" {
+		content, err := ioutil.ReadFile(rulesFile) // #nosec G304
+		if err != nil {
+			return err
+		}
+		cache.AddInput("rules-file", content)
+	}
+	for _, p := range plugins {
+		identity, err := p.Identity()
+		if err != nil {
+			return fmt.Errorf("plugin %s: %v", p.Name(), err)
+		}
+		cache.AddInput("plugin "+p.Name(), identity)
+	}
+	return nil
+}
+
+// applyBaseline filters out the issues recorded in the baseline file, after
+// writing the current issues into it when requested
+func applyBaseline(baselineFile string, write bool, issues []*gosec.Issue) ([]*gosec.Issue, *gosec.BaselineMetrics, error) {
+	if baselineFile == "" {
+		baselineFile = defaultBaseline
+	}
+	root, err := filepath.Abs(filepath.Dir(baselineFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	if write {
+		if err := baseline.New(root, issues).Save(baselineFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	known, err := baseline.Load(baselineFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	filtered, metrics := known.Filter(root, issues)
+	return filtered, metrics, nil
+}
+
+func loadRules(include, exclude string) rules.RuleList {
+	var filters []rules.RuleFilter
+	if include != "" {
+		logger.Printf("Including rules: %s", include)
+		including := strings.Split(include, ",")
+		filters = append(filters, rules.NewRuleFilter(false, including...))
+	} else {
+		logger.Println("Including rules: default")
+	}
+
+	if exclude != "" {
+		logger.Printf("Excluding rules: %s", exclude)
+		excluding := strings.Split(exclude, ",")
+		filters = append(filters, rules.NewRuleFilter(true, excluding...))
+	} else {
+		logger.Println("Excluding rules: default")
+	}
+	return rules.Generate(filters...)
+}
+
+func getRootPaths(paths []string) []string {
+	rootPaths := []string{}
+	for _, path := range paths {
+		rootPath, err := gosec.RootPath(path)
+		if err != nil {
+			logger.Fatal(fmt.Errorf("failed to get the root path of the projects: %s", err))
+		}
+		rootPaths = append(rootPaths, rootPath)
+	}
+	return rootPaths
+}
+
+// If verbose is defined it overwrites the defined format
+// Otherwise the actual format is used
+func getPrintedFormat(format string, verbose string) string {
+	if verbose != "" {
+		return verbose
+	}
+	return format
+}
+
+func printReport(format string, color bool, rootPaths []string, reportInfo *gosec.ReportInfo) error {
+	err := report.CreateReport(os.Stdout, format, color, rootPaths, reportInfo)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func saveReport(filename, format string, rootPaths []string, reportInfo *gosec.ReportInfo) error {
+	outfile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer outfile.Close() // #nosec G307
+	err = report.CreateReport(outfile, format, false, rootPaths, reportInfo)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func convertToScore(severity string) (gosec.Score, error) {
+	severity = strings.ToLower(severity)
+	switch severity {
+	case "low":
+		return gosec.Low, nil
+	case "medium":
+		return gosec.Medium, nil
+	case "high":
+		return gosec.High, nil
+	default:
+		return gosec.Low, fmt.Errorf("provided severity '%s' not valid. Valid options: low, medium, high", severity)
+	}
+}
+
+// readStdin reads the content of the file from stdin, and returns the overlay
+// holding it
+func readStdin(filename string) (map[string][]byte, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("the -stdin-filename flag is required with -stdin")
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading stdin: %v", err)
+	}
+	return map[string][]byte{path: content}, nil
+}
+
+// filterIssuesByFile keeps the issues of the files of the overlay
+func filterIssuesByFile(issues []*gosec.Issue, overlay map[string][]byte) []*gosec.Issue {
+	result := []*gosec.Issue{}
+	for _, issue := range issues {
+		if _, ok := overlay[issue.File]; ok {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+// Main runs the gosec command line with the arguments of the process
+func Main() {
+	// Makes sure some version information is set
+	prepareVersionInfo()
+
+	// Setup usage description
+	flag.Usage = usage
+
+	// Setup the excluded folders from scan
+	flag.Var(&flagDirsExclude, "exclude-dir", "Exclude folder from scan (can be specified multiple times)")
+	err := flag.Set("exclude-dir", "vendor")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: failed to exclude the %q directory from scan", "vendor")
+	}
+	err = flag.Set("exclude-dir", ".git")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: failed to exclude the %q directory from scan", ".git")
+	}
+
+	// Parse command line arguments
+	flag.Parse()
+
+	if *flagVersion {
+		fmt.Printf("Version: %s\nGit tag: %s\nBuild date: %s\n", Version, GitTag, BuildDate)
+		os.Exit(0)
+	}
+
+	// Ensure at least one file was specified
+	if flag.NArg() == 0 && !*flagStdin {
+		fmt.Fprintf(os.Stderr, "\nError: FILE [FILE...] or './...' expected\n") // #nosec
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Setup logging
+	logWriter := os.Stderr
+	if *flagLogfile != "" {
+		var e error
+		logWriter, e = os.Create(*flagLogfile)
+		if e != nil {
+			flag.Usage()
+			log.Fatal(e)
+		}
+	}
+
+	if *flagQuiet {
+		logger = log.New(ioutil.Discard, "", 0)
+	} else {
+		logger = log.New(logWriter, "[gosec] ", log.LstdFlags)
+	}
+
+	failSeverity, err := convertToScore(*flagSeverity)
+	if err != nil {
+		logger.Fatalf("Invalid severity value: %v", err)
+	}
+
+	failConfidence, err := convertToScore(*flagConfidence)
+	if err != nil {
+		logger.Fatalf("Invalid confidence value: %v", err)
+	}
+
+	// Load the analyzer configuration
+	config, err := loadConfig(*flagConfig)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	platforms, err := gosec.ParsePlatforms(*flagPlatforms)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	// Stop the scan and the plugins when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Start the plugins declared in the configuration, and register their rules
+	plugins, err := plugin.Load(ctx, config)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer plugin.Close(plugins)
+
+	// Register the custom rules
+	if *flagRulesFile != "" {
+		if err := rules.RegisterRulesFile(*flagRulesFile); err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	// Load enabled rule definitions
+	ruleDefinitions := loadRules(*flagRulesInclude, *flagRulesExclude)
+	if len(ruleDefinitions) == 0 {
+		logger.Fatal("No rules are configured")
+	}
+
+	// Serve an editor with the language server protocol
+	if flag.NArg() == 1 && flag.Arg(0) == "lsp" {
+		if err := serveLSP(config, ruleDefinitions, logger); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+
+	// Read the unsaved file from stdin, and check the package of the file
+	args := flag.Args()
+	var overlay map[string][]byte
+	if *flagStdin {
+		overlay, err = readStdin(*flagStdinFilename)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if len(args) == 0 {
+			args = []string{filepath.Dir(*flagStdinFilename)}
+		}
+	}
+
+	var buildTags []string
+	if *flagBuildTags != "" {
+		buildTags = strings.Split(*flagBuildTags, ",")
+	}
+
+	opts := gosec.Options{
+		Patterns:          args,
+		Rules:             ruleDefinitions.Builders(),
+		Config:            config,
+		Severity:          failSeverity,
+		Confidence:        failConfidence,
+		Tests:             *flagScanTests,
+		BuildTags:         buildTags,
+		ExcludeDirs:       flagDirsExclude,
+		Concurrency:       *flagConcurrency,
+		SortIssues:        *flagSortIssues,
+		TrackSuppressions: *flagTrackSuppressions,
+		StaleNosec:        *flagStaleNosec,
+		ProfileRules:      *flagProfileRules,
+		Platforms:         platforms,
+		Overlay:           overlay,
+		Logger:            logger,
+	}
+	if *flagCache {
+		opts.Cache, err = loadCache(*flagCacheDir)
+		if err == nil {
+			err = addCacheInputs(opts.Cache, ruleDefinitions, *flagRulesFile, plugins)
+		}
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	// Keep checking the packages which change, until interrupted
+	if *flagWatch {
+		if overlay != nil {
+			logger.Fatal("The -watch and -stdin flags cannot be used together")
+		}
+		if *flagDiff != "" || *flagBaseline != "" || *flagWriteBaseline {
+			logger.Fatal("The -watch flag cannot be used with -diff, -baseline or -write-baseline")
+		}
+		w, err := newWatcher(opts, logger)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		w.run(ctx, os.Stdout, *flagWatchInterval)
+		return
+	}
+
+	reportInfo, err := gosec.Scan(ctx, opts)
+	if err := plugin.Close(plugins); err != nil {
+		logger.Printf("Closing the plugins: %v", err)
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	// Collect the results
+	issues, metrics, errors := reportInfo.Issues, reportInfo.Stats, reportInfo.Errors
+	suppressed := reportInfo.Suppressed
+
+	// Filter out the issues recorded in the baseline. The baseline is written and
+	// matched with all the issues, before the issues are filtered by the changed
+	// lines or by the file read from stdin.
+	if *flagBaseline != "" || *flagWriteBaseline {
+		issues, metrics.Baseline, err = applyBaseline(*flagBaseline, *flagWriteBaseline, issues)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	// Filter the issues by the lines changed since the base revision
+	if *flagDiff != "" {
+		changes, err := diff.Load(".", *flagDiff)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		issues = changes.Filter(issues)
+	}
+
+	// Keep only the issues of the file read from stdin
+	if overlay != nil {
+		issues = filterIssuesByFile(issues, overlay)
+		suppressed = filterIssuesByFile(suppressed, overlay)
+	}
+
+	if metrics.NumFound != len(issues) {
+		metrics.NumFound = len(issues)
+	}
+
+	failures := reportInfo.RuleFailures
+
+	// Exit quietly if nothing was found
+	if len(issues) == 0 && len(failures) == 0 && *flagQuiet {
+		os.Exit(0)
+	}
+
+	// Create output report
+	rootPaths := getRootPaths(args)
+
+	reportInfo = gosec.NewReportInfo(issues, metrics, errors).
+		WithSuppressed(suppressed).
+		WithRuleFailures(failures).
+		WithVersion(Version)
+
+	if *flagOutput == "" || *flagStdOut {
+		fileFormat := getPrintedFormat(*flagFormat, *flagVerbose)
+		if err := printReport(fileFormat, *flagColor, rootPaths, reportInfo); err != nil {
+			logger.Fatal((err))
+		}
+	}
+	if *flagOutput != "" {
+		if err := saveReport(*flagOutput, *flagFormat, rootPaths, reportInfo); err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	if *flagProfileRules && metrics.Profile != nil {
+		printProfile(os.Stderr, metrics.Profile)
+	}
+
+	// Finalize logging
+	logWriter.Close() // #nosec
+
+	if *flagNoFail {
+		return
+	}
+
+	// Did a rule fail? If so exit 3, since the scan is incomplete
+	if len(failures) > 0 {
+		os.Exit(exitRuleFailure)
+	}
+
+	// Do we have an issue? If so exit 1 unless NoFail is set
+	if len(issues) > 0 || len(errors) > 0 {
+		os.Exit(1)
+	}
+}
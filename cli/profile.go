@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/securego/gosec/v2"
+)
+
+// maxProfiledPackages is the number of packages listed in the profile summary
+const maxProfiledPackages = 10
+
+// printProfile writes a summary of the profile, with the slowest rules and
+// packages first
+func printProfile(w io.Writer, profile *gosec.Profile) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "Rule\tCalls\tIssues\tTotal\tMax\tAverage")
+	for _, id := range profile.SortedRules() {
+		rule := profile.Rules[id]
+		var average time.Duration
+		if rule.Calls > 0 {
+			average = rule.Total / time.Duration(rule.Calls)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n", id, rule.Calls, rule.Issues,
+			rule.Total.Round(time.Microsecond), rule.Max.Round(time.Microsecond), average.Round(time.Microsecond))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Package\tLoad")
+	for i, pkg := range profile.SortedPackages() {
+		if i == maxProfiledPackages {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", pkg.Path, pkg.Load.Round(time.Millisecond))
+	}
+	tw.Flush() // #nosec G104
+}
@@ -1,6 +1,6 @@
-package main
+package cli
 
-// Version is the build version
+// Version is the build version, set by the main package
 var Version string
 
 // GitTag is the git tag of the build
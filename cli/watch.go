@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/baseline"
+)
+
+// watcher checks again the packages whose Go files change, along with the packages
+// which import them, and reports the issues added or resolved since the previous
+// check. The rules are loaded once, and the import graph of the packages is kept
+// between the checks.
+type watcher struct {
+	opts     gosec.Options
+	scanner  *gosec.Scanner
+	excluded []*regexp.Regexp
+	root     string
+	logger   *log.Logger
+	packages map[string]*watchedPackage // keyed by absolute directory
+	modules  map[string]string          // module path keyed by module directory
+}
+
+// watchedPackage holds the state of a package at its last check
+type watchedPackage struct {
+	stamp   string
+	imports packageImports
+	issues  []*gosec.Issue
+	entries []baseline.Entry
+}
+
+// packageImports holds the import path of a package and the paths it imports
+type packageImports struct {
+	path    string
+	imports []string
+}
+
+// watchResult holds the changes found by a check
+type watchResult struct {
+	checked  int
+	added    []*gosec.Issue
+	resolved []*gosec.Issue
+}
+
+// newWatcher creates a watcher of the packages selected by the options, which are
+// checked with the same options
+func newWatcher(opts gosec.Options, logger *log.Logger) (*watcher, error) {
+	root, err := filepath.Abs(".")
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := gosec.NewScanner(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &watcher{
+		opts:     opts,
+		scanner:  scanner,
+		excluded: gosec.ExcludedDirsRegExp(opts.ExcludeDirs),
+		root:     root,
+		logger:   logger,
+		packages: make(map[string]*watchedPackage),
+		modules:  make(map[string]string),
+	}, nil
+}
+
+// run checks the packages at each interval and prints the changes, until the
+// context is canceled
+func (w *watcher) run(ctx context.Context, out io.Writer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := w.check(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Println("Watching:", err)
+		} else if err == nil && result.checked > 0 {
+			printWatchResult(out, result)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check finds the packages whose Go files were added, changed or removed, checks
+// them again along with the packages which import them, and compares their issues
+// with the previous ones. The issues are compared by fingerprint, as in the
+// baseline, hence the issues moved by the edits of the file are neither added nor
+// resolved.
+func (w *watcher) check(ctx context.Context) (*watchResult, error) {
+	dirs, err := w.packageDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &watchResult{}
+	stamps := make(map[string]string, len(dirs))
+	changed := make(map[string]packageImports)
+	for _, dir := range dirs {
+		stamp, err := goFilesStamp(dir)
+		if err != nil {
+			return nil, err
+		}
+		stamps[dir] = stamp
+		if pkg, ok := w.packages[dir]; !ok || pkg.stamp != stamp {
+			changed[dir] = w.packageImports(dir)
+		}
+	}
+	var removed []string
+	for dir, pkg := range w.packages {
+		if _, ok := stamps[dir]; !ok {
+			result.checked++
+			result.resolved = append(result.resolved, pkg.issues...)
+			removed = append(removed, pkg.imports.path)
+			delete(w.packages, dir)
+		}
+	}
+	if len(changed) == 0 && len(removed) == 0 {
+		return result, nil
+	}
+
+	checked := w.importers(changed, removed)
+	if len(checked) == 0 {
+		return result, nil
+	}
+	report, err := w.scanner.Scan(ctx, checked...)
+	if err != nil {
+		return nil, err
+	}
+	for file, errs := range report.Errors {
+		for _, e := range errs {
+			w.logger.Printf("Error in %s:%d:%d: %s", file, e.Line, e.Column, e.Err)
+		}
+	}
+	byDir := make(map[string][]*gosec.Issue)
+	for _, issue := range report.Issues {
+		dir := filepath.Dir(issue.File)
+		byDir[dir] = append(byDir[dir], issue)
+	}
+
+	for _, dir := range checked {
+		previous := w.packages[dir]
+		if previous == nil {
+			previous = &watchedPackage{}
+		}
+		imports, ok := changed[dir]
+		if !ok {
+			imports = previous.imports
+		}
+		pkg := &watchedPackage{stamp: stamps[dir], imports: imports, issues: byDir[dir]}
+		pkg.entries = baseline.Entries(w.root, pkg.issues)
+		result.added = append(result.added, missingIssues(pkg, previous)...)
+		result.resolved = append(result.resolved, missingIssues(previous, pkg)...)
+		w.packages[dir] = pkg
+	}
+	result.checked += len(checked)
+	return result, nil
+}
+
+// importers returns the sorted directories of the changed packages, along with the
+// directories of the packages which import, directly or not, a changed or a
+// removed package
+func (w *watcher) importers(changed map[string]packageImports, removed []string) []string {
+	importedBy := make(map[string][]string)
+	addEdges := func(dir string, imports packageImports) {
+		for _, path := range imports.imports {
+			importedBy[path] = append(importedBy[path], dir)
+		}
+	}
+	for dir, pkg := range w.packages {
+		if _, ok := changed[dir]; !ok {
+			addEdges(dir, pkg.imports)
+		}
+	}
+	for dir, imports := range changed {
+		addEdges(dir, imports)
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	queue := append([]string{}, removed...)
+	for dir, imports := range changed {
+		seen[dir] = true
+		dirs = append(dirs, dir)
+		queue = append(queue, imports.path)
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if path == "" {
+			continue
+		}
+		for _, dir := range importedBy[path] {
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			dirs = append(dirs, dir)
+			if pkg, ok := w.packages[dir]; ok {
+				queue = append(queue, pkg.imports.path)
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// packageImports returns the import path of the package in the directory, and the
+// paths imported by its files and by its tests when they are checked. The import
+// path is empty when the directory is not in a module.
+func (w *watcher) packageImports(dir string) packageImports {
+	ctx := build.Default
+	ctx.BuildTags = w.opts.BuildTags
+	// the files which cannot be parsed are checked and reported by the scan
+	pkg, _ := ctx.ImportDir(dir, build.ImportComment)
+	imports := packageImports{path: w.importPath(dir)}
+	if pkg == nil {
+		return imports
+	}
+	imports.imports = append(imports.imports, pkg.Imports...)
+	if w.opts.Tests {
+		imports.imports = append(imports.imports, pkg.TestImports...)
+		imports.imports = append(imports.imports, pkg.XTestImports...)
+	}
+	return imports
+}
+
+// importPath returns the import path of the package in the directory, built from
+// the path of its module
+func (w *watcher) importPath(dir string) string {
+	for moduleDir := dir; ; {
+		path, ok := w.modules[moduleDir]
+		if !ok {
+			path = modulePath(filepath.Join(moduleDir, "go.mod"))
+			w.modules[moduleDir] = path
+		}
+		if path != "" {
+			rel, err := filepath.Rel(moduleDir, dir)
+			if err != nil {
+				return ""
+			}
+			if rel == "." {
+				return path
+			}
+			return path + "/" + filepath.ToSlash(rel)
+		}
+		parent := filepath.Dir(moduleDir)
+		if parent == moduleDir {
+			return ""
+		}
+		moduleDir = parent
+	}
+}
+
+// modulePath reads the module path declared in a go.mod file, which is empty when
+// the file doesn't exist
+func modulePath(gomod string) string {
+	content, err := ioutil.ReadFile(gomod) // #nosec G304
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// packageDirs returns the absolute directories of the watched packages
+func (w *watcher) packageDirs() ([]string, error) {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, path := range w.opts.Patterns {
+		paths, err := gosec.PackagePaths(path, w.excluded)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range paths {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[abs] {
+				seen[abs] = true
+				dirs = append(dirs, abs)
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// missingIssues returns the issues of the package which are not in the other
+// package. An entry matches a single issue, hence the duplicated issues are
+// missing when they exceed the count of the other ones.
+func missingIssues(pkg, other *watchedPackage) []*gosec.Issue {
+	known := make(map[baseline.Entry]int, len(other.entries))
+	for _, entry := range other.entries {
+		known[entry]++
+	}
+	var missing []*gosec.Issue
+	for i, entry := range pkg.entries {
+		if known[entry] > 0 {
+			known[entry]--
+			continue
+		}
+		missing = append(missing, pkg.issues[i])
+	}
+	return missing
+}
+
+// goFilesStamp returns a stamp of the Go files of the directory, which changes
+// when a file is added, removed or modified
+func goFilesStamp(dir string) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var stamp strings.Builder
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".go" {
+			continue
+		}
+		fmt.Fprintf(&stamp, "%s:%d:%d;", file.Name(), file.Size(), file.ModTime().UnixNano())
+	}
+	return stamp.String(), nil
+}
+
+// printWatchResult prints the added issues with a "+" and the resolved issues
+// with a "-", followed by a summary of the check
+func printWatchResult(out io.Writer, result *watchResult) {
+	for _, issue := range result.added {
+		fmt.Fprintf(out, "+ %s\n", formatWatchedIssue(issue))
+	}
+	for _, issue := range result.resolved {
+		fmt.Fprintf(out, "- %s\n", formatWatchedIssue(issue))
+	}
+	fmt.Fprintf(out, "[%s] Checked %d packages: %d issues added, %d resolved\n",
+		time.Now().Format("15:04:05"), result.checked, len(result.added), len(result.resolved))
+}
+
+func formatWatchedIssue(issue *gosec.Issue) string {
+	cwe := ""
+	if issue.Cwe != nil {
+		cwe = " (" + issue.Cwe.SprintID() + ")"
+	}
+	return fmt.Sprintf("[%s:%s:%s] %s%s: %s (Confidence: %s, Severity: %s)",
+		issue.File, issue.Line, issue.Col, issue.RuleID, cwe, issue.What, issue.Confidence, issue.Severity)
+}
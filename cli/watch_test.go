@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Watching the packages", func() {
+	const (
+		weak = `package main
+
+import "crypto/md5"
+
+func main() {
+	println(md5.Sum(nil)[0])
+}
+`
+		moved = `package main
+
+import "crypto/md5"
+
+// main prints a digest
+func main() {
+	println(md5.Sum(nil)[0])
+}
+`
+		fixed = `package main
+
+import "crypto/sha256"
+
+func main() {
+	println(sha256.Sum256(nil)[0])
+}
+`
+	)
+
+	var (
+		pkg  *testutils.TestPackage
+		w    *watcher
+		file string
+	)
+
+	BeforeEach(func() {
+		pkg = testutils.NewTestPackage()
+		pkg.AddFile("main.go", weak)
+		Expect(pkg.Build()).Should(Succeed())
+		file = filepath.Join(pkg.Path, "main.go")
+
+		logger, _ := testutils.NewLogger()
+		var err error
+		w, err = newWatcher(gosec.Options{
+			Patterns: []string{pkg.Path + "/..."},
+			Rules:    rules.Generate(rules.NewRuleFilter(false, "G401")).Builders(),
+			Logger:   logger,
+		}, logger)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		pkg.Close()
+	})
+
+	It("should report only the issues added or resolved since the previous check", func() {
+		result, err := w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(1))
+		Expect(result.added).To(HaveLen(1))
+		Expect(result.added[0].RuleID).To(Equal("G401"))
+		Expect(result.resolved).To(BeEmpty())
+
+		result, err = w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(0))
+
+		Expect(ioutil.WriteFile(file, []byte(moved), 0600)).Should(Succeed())
+		result, err = w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(1))
+		Expect(result.added).To(BeEmpty())
+		Expect(result.resolved).To(BeEmpty())
+
+		Expect(ioutil.WriteFile(file, []byte(fixed), 0600)).Should(Succeed())
+		result, err = w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.added).To(BeEmpty())
+		Expect(result.resolved).To(HaveLen(1))
+		Expect(result.resolved[0].Line).To(Equal("7"))
+	})
+
+	It("should check only the changed packages and resolve the issues of the removed ones", func() {
+		other := filepath.Join(pkg.Path, "other")
+		Expect(os.Mkdir(other, 0700)).Should(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(other, "main.go"), []byte(weak), 0600)).Should(Succeed())
+		result, err := w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(2))
+		Expect(result.added).To(HaveLen(2))
+
+		Expect(os.RemoveAll(other)).Should(Succeed())
+		Expect(ioutil.WriteFile(file, []byte(moved), 0600)).Should(Succeed())
+		result, err = w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(2))
+		Expect(result.added).To(BeEmpty())
+		Expect(result.resolved).To(HaveLen(1))
+		Expect(result.resolved[0].File).To(Equal(filepath.Join(other, "main.go")))
+	})
+
+	It("should check again the packages which import a changed package", func() {
+		Expect(ioutil.WriteFile(filepath.Join(pkg.Path, "go.mod"), []byte("module example.com/app\n\ngo 1.16\n"), 0600)).Should(Succeed())
+		lib := filepath.Join(pkg.Path, "lib")
+		Expect(os.Mkdir(lib, 0700)).Should(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n\nconst Name = \"lib\"\n"), 0600)).Should(Succeed())
+		tool := filepath.Join(pkg.Path, "tool")
+		Expect(os.Mkdir(tool, 0700)).Should(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(tool, "tool.go"), []byte("package tool\n\nimport \"example.com/app/lib\"\n\nvar Name = lib.Name\n"), 0600)).Should(Succeed())
+		result, err := w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(3))
+
+		Expect(ioutil.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n\nconst Name = \"library\"\n"), 0600)).Should(Succeed())
+		Expect(w.importers(map[string]packageImports{lib: w.packageImports(lib)}, nil)).To(Equal([]string{lib, tool}))
+		result, err = w.check(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.checked).To(Equal(2))
+	})
+})
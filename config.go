@@ -24,6 +24,22 @@ const (
 	Audit GlobalOption = "audit"
 	// NoSecAlternative global option alternative for #nosec directive
 	NoSecAlternative GlobalOption = "#nosec"
+	// TaintAnalysis global option which enables the taint analysis in the injection rules
+	TaintAnalysis GlobalOption = "taint"
+	// NoSecJustification global option which requires a justification for each #nosec directive
+	NoSecJustification GlobalOption = "nosec-justification"
+	// GeneratedCode global option which selects how the issues of the generated files
+	// are handled: report (default), downgrade or skip
+	GeneratedCode GlobalOption = "generated"
+)
+
+const (
+	// TaintSection is the configuration section holding additional sources,
+	// sanitizers and sinks for the taint analysis
+	TaintSection = "taint"
+	// PluginsSection is the configuration section declaring the external rule
+	// executables, see the plugin package
+	PluginsSection = "plugins"
 )
 
 // Config is used to provide configuration and customization to each of the rules.
@@ -24,6 +24,11 @@ var (
 	data = map[string]*Weakness{}
 
 	weaknesses = []*Weakness{
+		{
+			ID:          "1164",
+			Description: "The program contains code that is not essential for execution, i.e. makes no state changes and has no side effects that alter data or control flow, such that removal of the code would have no impact to functionality or correctness.",
+			Name:        "Irrelevant Code",
+		},
 		{
 			ID:          "118",
 			Description: "The software does not restrict or incorrectly restricts operations within the boundaries of a resource that is accessed using an index or pointer, such as memory or files.",
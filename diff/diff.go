@@ -0,0 +1,239 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff restricts the gosec report to the lines which were added or
+// changed in a git repository since a base revision.
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/securego/gosec/v2"
+)
+
+// LineRange is an inclusive range of lines
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes maps the absolute paths of the changed files to the ranges of the
+// added or changed lines
+type Changes map[string][]LineRange
+
+// Load collects the lines added or changed since the base revision in the git
+// repository containing dir. The uncommitted changes and the untracked files are
+// included, as they are part of the scanned code. The base revision must name a
+// commit.
+func Load(dir string, baseRef string) (Changes, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	rootDir := resolvePath(strings.TrimSpace(string(root)))
+
+	// the revision is verified so that it can't be taken as an option of git diff
+	base, err := git(dir, "rev-parse", "--verify", "--end-of-options", baseRef+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("invalid base revision %q: %v", baseRef, err)
+	}
+	out, err := git(dir, "diff", "--unified=0", "--no-color", "--no-ext-diff", "--no-renames", strings.TrimSpace(string(base)), "--")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := Parse(bytes.NewReader(out), rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := git(dir, "ls-files", "--others", "--exclude-standard", "-z", "--full-name", ":/")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(string(untracked), "\x00") {
+		if name == "" {
+			continue
+		}
+		// the whole file is new
+		changes.add(filepath.Join(rootDir, filepath.FromSlash(name)), LineRange{Start: 1, End: math.MaxInt32})
+	}
+	return changes, nil
+}
+
+// Parse reads the added or changed lines from the output of git diff, without
+// context lines. The file paths are relative to the root directory. The section
+// of each file starts with a "diff --git" line, and the lines of each hunk are
+// counted from its header, so that a removed or added line is never taken for a
+// header, e.g. a removed line starting with "-- ".
+func Parse(r io.Reader, root string) (Changes, error) {
+	changes := make(Changes)
+	var file string
+	// header is set between the "diff --git" line of a file and its first hunk
+	header := false
+	// removed and added are the lines of the current hunk not read yet
+	removed, added := 0, 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if removed > 0 || added > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				removed--
+			case strings.HasPrefix(line, "+"):
+				added--
+			case strings.HasPrefix(line, " "):
+				removed--
+				added--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = ""
+			header = true
+		case header && strings.HasPrefix(line, "+++ "):
+			name, err := parseFileName(strings.TrimPrefix(line, "+++ "))
+			if err != nil {
+				return nil, err
+			}
+			if name != "" {
+				file = filepath.Join(root, filepath.FromSlash(name))
+			}
+		case strings.HasPrefix(line, "@@ "):
+			header = false
+			lines, oldLines, err := parseHunk(line)
+			if err != nil {
+				return nil, err
+			}
+			removed, added = oldLines, lines.End-lines.Start+1
+			if file != "" && lines.End >= lines.Start {
+				changes.add(file, lines)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Contains checks if any line of the range was added or changed in the file
+func (c Changes) Contains(file string, lines LineRange) bool {
+	for _, changed := range c[resolvePath(file)] {
+		if lines.Start <= changed.End && changed.Start <= lines.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the issues which overlap the added or changed lines
+func (c Changes) Filter(issues []*gosec.Issue) []*gosec.Issue {
+	result := make([]*gosec.Issue, 0, len(issues))
+	for _, issue := range issues {
+		start, end, err := issue.Range()
+		if err != nil || c.Contains(issue.File, LineRange{Start: start.Line, End: end.Line}) {
+			// keep the issues which cannot be located rather than hiding them
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+func (c Changes) add(file string, lines LineRange) {
+	c[file] = append(c[file], lines)
+}
+
+// parseFileName extracts the path of the new file from a "+++" header. The
+// deleted files have no name.
+func parseFileName(name string) (string, error) {
+	if name == "/dev/null" {
+		return "", nil
+	}
+	if strings.HasPrefix(name, `"`) {
+		unquoted, err := strconv.Unquote(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid file name in diff %s: %v", name, err)
+		}
+		name = unquoted
+	}
+	return strings.TrimPrefix(name, "b/"), nil
+}
+
+// parseHunk extracts the range of the new lines from a hunk header, e.g.
+// "@@ -10,2 +12,3 @@", along with the number of old lines. A range with no lines
+// is returned for the deletions.
+func parseHunk(header string) (LineRange, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	_, oldLines, err := parseRange(strings.TrimPrefix(fields[1], "-"))
+	if err != nil {
+		return LineRange{}, 0, fmt.Errorf("invalid hunk header %q: %v", header, err)
+	}
+	start, count, err := parseRange(strings.TrimPrefix(fields[2], "+"))
+	if err != nil {
+		return LineRange{}, 0, fmt.Errorf("invalid hunk header %q: %v", header, err)
+	}
+	return LineRange{Start: start, End: start + count - 1}, oldLines, nil
+}
+
+// parseRange parses the start and the number of lines of a hunk range, e.g.
+// "12,3". The number of lines is 1 when it's omitted.
+func parseRange(r string) (int, int, error) {
+	parts := strings.SplitN(r, ",", 2)
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if len(parts) == 2 {
+		if count, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false"}, args...)...) // #nosec G204
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// resolvePath makes a path absolute and resolves the symbolic links, so that the
+// paths reported by git and by the analyzer can be compared
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
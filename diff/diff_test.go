@@ -0,0 +1,172 @@
+package diff_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/diff"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 3f1e2a1..8c2d9b4 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ import "os"
++	"os/exec"
++	"fmt"
+@@ -10 +12 @@ func main() {
+-	run("ls")
++	run(os.Args[1])
+@@ -20,3 +21,0 @@ func run(name string) {
+-	// removed
+-	// removed
+-	// removed
+diff --git a/old.go b/old.go
+deleted file mode 100644
+index 3f1e2a1..0000000
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-
+diff --git "a/with space.go" "b/with space.go"
+index 3f1e2a1..8c2d9b4 100644
+--- "a/with space.go"
++++ "b/with space.go"
+@@ -1 +1 @@
+-package main
++package main // changed
+`
+
+var _ = Describe("Diff", func() {
+	var root string
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "diff")
+		Expect(err).ShouldNot(HaveOccurred())
+		root, err = filepath.EvalSymlinks(root)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	Context("when parsing a diff", func() {
+		It("should collect the added and changed lines", func() {
+			changes, err := diff.Parse(strings.NewReader(sampleDiff), root)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(changes).To(Equal(diff.Changes{
+				filepath.Join(root, "main.go"): {
+					{Start: 4, End: 5},
+					{Start: 12, End: 12},
+				},
+				filepath.Join(root, "with space.go"): {
+					{Start: 1, End: 1},
+				},
+			}))
+		})
+
+		It("should not take the removed and added lines for file headers", func() {
+			changes, err := diff.Parse(strings.NewReader(`diff --git a/query.go b/query.go
+index 3f1e2a1..8c2d9b4 100644
+--- a/query.go
++++ b/query.go
+@@ -5 +5,2 @@ func users() string {
+--- select the users
++++ select the active users
++-- with their groups
+@@ -9,0 +11 @@ SELECT *
++WHERE active
+`), root)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(changes).To(Equal(diff.Changes{
+				filepath.Join(root, "query.go"): {
+					{Start: 5, End: 6},
+					{Start: 11, End: 11},
+				},
+			}))
+		})
+
+		It("should report an invalid hunk header", func() {
+			_, err := diff.Parse(strings.NewReader("--- a/main.go\n+++ b/main.go\n@@ -1 +x @@\n"), root)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when filtering the issues", func() {
+		It("should keep the issues overlapping the changed lines", func() {
+			changes, err := diff.Parse(strings.NewReader(sampleDiff), root)
+			Expect(err).ShouldNot(HaveOccurred())
+			file := filepath.Join(root, "main.go")
+			issues := []*gosec.Issue{
+				{File: file, Line: "4"},
+				{File: file, Line: "6"},
+				{File: file, Line: "10-12"},
+				{File: file, Line: "13-15"},
+				{File: filepath.Join(root, "other.go"), Line: "4"},
+			}
+			Expect(changes.Filter(issues)).To(Equal([]*gosec.Issue{issues[0], issues[2]}))
+		})
+	})
+
+	Context("when loading the changes from a git repository", func() {
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=gosec", "-c", "user.email=gosec@example.com"}, args...)...)
+			cmd.Dir = root
+			out, err := cmd.CombinedOutput()
+			Expect(err).ShouldNot(HaveOccurred(), string(out))
+		}
+
+		It("should include the uncommitted changes and the untracked files", func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not available")
+			}
+			git("init", "-q")
+			main := filepath.Join(root, "main.go")
+			Expect(ioutil.WriteFile(main, []byte("package main\n\nfunc main() {\n}\n"), 0600)).Should(Succeed())
+			git("add", "main.go")
+			git("commit", "-q", "-m", "base")
+			git("tag", "base")
+
+			Expect(ioutil.WriteFile(main, []byte("package main\n\nfunc main() {\n\tprintln()\n}\n"), 0600)).Should(Succeed())
+			extra := filepath.Join(root, "extra.go")
+			Expect(ioutil.WriteFile(extra, []byte("package main\n"), 0600)).Should(Succeed())
+
+			changes, err := diff.Load(root, "base")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(changes.Contains(main, diff.LineRange{Start: 4, End: 4})).To(BeTrue())
+			Expect(changes.Contains(main, diff.LineRange{Start: 1, End: 3})).To(BeFalse())
+			Expect(changes.Contains(extra, diff.LineRange{Start: 1, End: 1})).To(BeTrue())
+		})
+
+		It("should report an unknown revision", func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not available")
+			}
+			git("init", "-q")
+			_, err := diff.Load(root, "unknown")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should not take the revision as an option", func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not available")
+			}
+			git("init", "-q")
+			output := filepath.Join(root, "output")
+			_, err := diff.Load(root, "--output="+output)
+			Expect(err).To(MatchError(ContainSubstring("invalid base revision")))
+			_, err = os.Stat(output)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -20,6 +20,16 @@ func NewError(line, column int, err string) *Error {
 	}
 }
 
+// RuleFailure is used when a rule returns an error or panics while matching a node
+type RuleFailure struct {
+	RuleID string `json:"rule_id"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Err    string `json:"error"`
+	Stack  string `json:"stack,omitempty" yaml:"stack,omitempty"` // Set when the rule panicked
+}
+
 // sortErros sorts the golang erros by line
 func sortErrors(allErrors map[string][]Error) {
 	for _, errors := range allErrors {
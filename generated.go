@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+)
+
+// The values of the GeneratedCode global option
+const (
+	// GeneratedReport reports the issues of the generated files as the other issues
+	GeneratedReport = "report"
+	// GeneratedDowngrade reports the issues of the generated files with a low severity
+	GeneratedDowngrade = "downgrade"
+	// GeneratedSkip doesn't check the generated files
+	GeneratedSkip = "skip"
+)
+
+// generatedHeader matches the comment marking the generated files, as defined in
+// https://golang.org/s/generatedcode
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// IsGeneratedFile checks if the file starts with a "// Code generated ... DO NOT EDIT."
+// comment before the package clause
+func IsGeneratedFile(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			return false
+		}
+		for _, comment := range group.List {
+			if generatedHeader.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateGeneratedMode checks the value of the GeneratedCode global option
+func ValidateGeneratedMode(mode string) error {
+	switch mode {
+	case GeneratedReport, GeneratedDowngrade, GeneratedSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid generated code mode %q, expected %s, %s or %s",
+		mode, GeneratedReport, GeneratedDowngrade, GeneratedSkip)
+}
+
+// generatedMode returns how the generated files are handled, according to the
+// GeneratedCode global option. The generated files are reported by default.
+func (gosec *Analyzer) generatedMode() string {
+	mode, err := gosec.config.GetGlobal(GeneratedCode)
+	if err != nil || ValidateGeneratedMode(mode) != nil {
+		return GeneratedReport
+	}
+	return mode
+}
+
+// markGenerated flags the issue as found in a generated file, and lowers its
+// severity when the generated files are downgraded
+func markGenerated(issue *Issue, mode string) {
+	issue.Generated = true
+	if mode == GeneratedDowngrade {
+		issue.Severity = Low
+	}
+}
@@ -0,0 +1,155 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goanalysis exposes the gosec rules as golang.org/x/tools/go/analysis
+// analyzers, in order to run them from any go/analysis driver (e.g. multichecker,
+// nogo or golangci-lint).
+package goanalysis
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+)
+
+// RuleSetName is the name of the analyzer which runs all the rules at once
+const RuleSetName = "gosec"
+
+// NewAnalyzer wraps a single gosec rule into an analyzer named after the rule ID
+func NewAnalyzer(def rules.RuleDefinition, conf gosec.Config) *analysis.Analyzer {
+	doc := fmt.Sprintf("%s: %s", def.ID, def.Description)
+	return newAnalyzer(def.ID, doc, rules.RuleList{def.ID: def}, conf)
+}
+
+// NewAnalyzers wraps each rule from the rule list into its own analyzer. The
+// analyzers are sorted by rule ID.
+func NewAnalyzers(rl rules.RuleList, conf gosec.Config) []*analysis.Analyzer {
+	ids := make([]string, 0, len(rl))
+	for id := range rl {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	analyzers := make([]*analysis.Analyzer, 0, len(ids))
+	for _, id := range ids {
+		analyzers = append(analyzers, NewAnalyzer(rl[id], conf))
+	}
+	return analyzers
+}
+
+// NewRuleSetAnalyzer wraps all the rules from the rule list into a single
+// analyzer, which walks the AST only once per file.
+func NewRuleSetAnalyzer(rl rules.RuleList, conf gosec.Config) *analysis.Analyzer {
+	ids := make([]string, 0, len(rl))
+	for id := range rl {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var doc strings.Builder
+	doc.WriteString("gosec inspects source code for security problems\n\nRules:\n")
+	for _, id := range ids {
+		fmt.Fprintf(&doc, "\t%s: %s\n", id, rl[id].Description)
+	}
+	return newAnalyzer(RuleSetName, doc.String(), rl, conf)
+}
+
+func newAnalyzer(name, doc string, rl rules.RuleList, conf gosec.Config) *analysis.Analyzer {
+	if conf == nil {
+		conf = gosec.NewConfig()
+	}
+	builders := rl.Builders()
+	return &analysis.Analyzer{
+		Name: name,
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return run(pass, builders, conf)
+		},
+	}
+}
+
+// run checks the package of the pass with a gosec analyzer and reports the
+// issues as diagnostics
+func run(pass *analysis.Pass, builders map[string]gosec.RuleBuilder, conf gosec.Config) (interface{}, error) {
+	analyzer := gosec.NewAnalyzer(conf, true, log.New(ioutil.Discard, "", 0))
+	analyzer.LoadRules(builders)
+	analyzer.Check(&packages.Package{
+		ID:         pass.Pkg.Path(),
+		Name:       pass.Pkg.Name(),
+		PkgPath:    pass.Pkg.Path(),
+		Fset:       pass.Fset,
+		Syntax:     pass.Files,
+		Types:      pass.Pkg,
+		TypesInfo:  pass.TypesInfo,
+		TypesSizes: pass.TypesSizes,
+	})
+
+	issues, _, _ := analyzer.Report()
+	for _, issue := range issues {
+		pass.Report(NewDiagnostic(pass, issue))
+	}
+	return nil, nil
+}
+
+// NewDiagnostic converts a gosec issue into a diagnostic. The category holds the
+// rule ID, while the message carries the CWE, the severity and the confidence.
+func NewDiagnostic(pass *analysis.Pass, issue *gosec.Issue) analysis.Diagnostic {
+	what := issue.What
+	if issue.Cwe != nil && issue.Cwe.ID != "" {
+		what = fmt.Sprintf("[%s] %s", issue.Cwe.SprintID(), issue.What)
+	}
+	pos, end := issuePos(pass, issue)
+	return analysis.Diagnostic{
+		Pos:      pos,
+		End:      end,
+		Category: issue.RuleID,
+		Message: fmt.Sprintf("%s (Rule:%s, Severity:%s, Confidence:%s)",
+			what, issue.RuleID, issue.Severity.String(), issue.Confidence.String()),
+	}
+}
+
+// issuePos finds the positions of the start and of the end of the issue in the
+// files of the pass. The end is unknown for the issues created without positions.
+func issuePos(pass *analysis.Pass, issue *gosec.Issue) (token.Pos, token.Pos) {
+	start, end, err := issue.Range()
+	if err != nil {
+		return token.NoPos, token.NoPos
+	}
+	if start.Column < 1 {
+		start.Column = 1
+	}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil || tf.Name() != issue.File {
+			continue
+		}
+		return filePos(tf, start), filePos(tf, end)
+	}
+	return token.NoPos, token.NoPos
+}
+
+// filePos converts a position of an issue into a position of the file
+func filePos(tf *token.File, pos gosec.Position) token.Pos {
+	if pos.Line < 1 || pos.Line > tf.LineCount() || pos.Column < 1 {
+		return token.NoPos
+	}
+	return tf.LineStart(pos.Line) + token.Pos(pos.Column-1)
+}
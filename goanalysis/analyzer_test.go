@@ -0,0 +1,84 @@
+package goanalysis_test
+
+import (
+	"golang.org/x/tools/go/analysis"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/goanalysis"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Analyzer", func() {
+	var (
+		pkg  *testutils.TestPackage
+		runs func(*analysis.Analyzer) []analysis.Diagnostic
+	)
+
+	BeforeEach(func() {
+		pkg = testutils.NewTestPackage()
+		pkg.AddFile("md5.go", testutils.SampleCodeG401[0].Code[0])
+		Expect(pkg.Build()).ShouldNot(HaveOccurred())
+		runs = func(a *analysis.Analyzer) []analysis.Diagnostic {
+			var diagnostics []analysis.Diagnostic
+			for _, p := range pkg.Pkgs() {
+				pass := &analysis.Pass{
+					Analyzer:   a,
+					Fset:       p.Fset,
+					Files:      p.Syntax,
+					Pkg:        p.Types,
+					TypesInfo:  p.TypesInfo,
+					TypesSizes: p.TypesSizes,
+					Report: func(d analysis.Diagnostic) {
+						diagnostics = append(diagnostics, d)
+					},
+				}
+				_, err := a.Run(pass)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			return diagnostics
+		}
+	})
+
+	AfterEach(func() {
+		pkg.Close()
+	})
+
+	It("should create a valid analyzer for each rule", func() {
+		analyzers := goanalysis.NewAnalyzers(rules.Generate(), nil)
+		Expect(analyzers).To(HaveLen(len(rules.Generate())))
+		Expect(analysis.Validate(analyzers)).ShouldNot(HaveOccurred())
+		Expect(analyzers[0].Name).To(Equal("G101"))
+	})
+
+	It("should report the issues of a single rule as diagnostics", func() {
+		def := rules.Generate(rules.NewRuleFilter(false, "G401"))["G401"]
+		diagnostics := runs(goanalysis.NewAnalyzer(def, gosec.NewConfig()))
+		Expect(diagnostics).To(HaveLen(testutils.SampleCodeG401[0].Errors))
+		Expect(diagnostics[0].Category).To(Equal("G401"))
+		Expect(diagnostics[0].Message).To(ContainSubstring("CWE-326"))
+		Expect(diagnostics[0].Message).To(ContainSubstring("Severity:MEDIUM"))
+		Expect(diagnostics[0].Pos.IsValid()).To(BeTrue())
+	})
+
+	It("should not report issues of rules which are not wrapped", func() {
+		def := rules.Generate(rules.NewRuleFilter(false, "G101"))["G101"]
+		diagnostics := runs(goanalysis.NewAnalyzer(def, nil))
+		Expect(diagnostics).To(BeEmpty())
+	})
+
+	It("should report the issues of the whole rule set", func() {
+		analyzer := goanalysis.NewRuleSetAnalyzer(rules.Generate(), nil)
+		Expect(analyzer.Name).To(Equal(goanalysis.RuleSetName))
+		diagnostics := runs(analyzer)
+		categories := []string{}
+		for _, d := range diagnostics {
+			categories = append(categories, d.Category)
+		}
+		Expect(categories).To(ContainElement("G401"))
+		Expect(categories).To(ContainElement("G501"))
+	})
+})
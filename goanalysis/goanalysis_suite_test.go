@@ -0,0 +1,13 @@
+package goanalysis_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestGoanalysis(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Goanalysis Suite")
+}
@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -273,6 +274,61 @@ func GetImportPath(name string, ctx *Context) (string, bool) {
 	return "", false
 }
 
+// StripVendor removes the vendor directory prefix from an import path, e.g.
+// "example.com/app/vendor/github.com/pkg/errors" becomes "github.com/pkg/errors"
+func StripVendor(path string) string {
+	if vendorIdx := strings.Index(path, vendorPath); vendorIdx >= 0 {
+		return path[vendorIdx+len(vendorPath):]
+	}
+	return path
+}
+
+// IsTainted reports whether tainted data reaches the argument of the call with
+// the given index. The second value is false when the taint analysis is not enabled
+// or it is not able to analyze the call, in which case the rules should fall back
+// to their own heuristics.
+func IsTainted(call *ast.CallExpr, arg int, ctx *Context) (bool, bool) {
+	if ctx.Taint == nil {
+		return false, false
+	}
+	tainted, err := ctx.Taint.IsTainted(call, arg)
+	if err != nil {
+		return false, false
+	}
+	return tainted, true
+}
+
+// TaintSinkArgs returns the indices of the arguments of the call checked by the
+// taint sinks of the rule. The second value is false when the taint analysis is
+// not enabled or the call is not a sink of the rule.
+func TaintSinkArgs(rule string, call *ast.CallExpr, ctx *Context) ([]int, bool) {
+	if ctx.Taint == nil {
+		return nil, false
+	}
+	return ctx.Taint.SinkArgs(rule, call)
+}
+
+// IsTaintedSink reports whether tainted data reaches any of the arguments of the
+// call checked by the taint sinks of the rule. The second value is false when the
+// call is not a sink of the rule or it cannot be analyzed, in which case the rules
+// should fall back to their own heuristics.
+func IsTaintedSink(rule string, call *ast.CallExpr, ctx *Context) (bool, bool) {
+	args, ok := TaintSinkArgs(rule, call, ctx)
+	if !ok {
+		return false, false
+	}
+	for _, arg := range args {
+		tainted, ok := IsTainted(call, arg, ctx)
+		if !ok {
+			return false, false
+		}
+		if tainted {
+			return true, true
+		}
+	}
+	return false, true
+}
+
 // GetLocation returns the filename and line number of an ast.Node
 func GetLocation(n ast.Node, ctx *Context) (string, int) {
 	fobj := ctx.FileSet.File(n.Pos())
@@ -417,6 +473,7 @@ func PackagePaths(root string, excludes []*regexp.Regexp) ([]string, error) {
 	for path := range paths {
 		result = append(result, path)
 	}
+	sort.Strings(result)
 	return result, nil
 }
 
@@ -17,12 +17,19 @@ package gosec
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/printer"
 	"go/token"
-	"os"
+	"io"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/securego/gosec/v2/cwe"
 )
@@ -43,17 +50,46 @@ const (
 // the beginning and after the end of a code snippet
 const SnippetOffset = 1
 
-// GetCweByRule retrieves a cwe weakness for a given RuleID
+// GetCweByRule retrieves a cwe weakness for a given RuleID. The weaknesses which
+// are unknown to gosec are referenced only by their ID.
 func GetCweByRule(id string) *cwe.Weakness {
+	ruleToCWEMutex.RLock()
 	cweID, ok := ruleToCWE[id]
+	ruleToCWEMutex.RUnlock()
 	if ok && cweID != "" {
-		return cwe.Get(cweID)
+		if weakness := cwe.Get(cweID); weakness != nil {
+			return weakness
+		}
+		return &cwe.Weakness{ID: cweID}
 	}
 	return nil
 }
 
+// RegisterCwe maps the issues of a rule to the CWE with the given ID, e.g. "79"
+func RegisterCwe(ruleID string, cweID string) {
+	ruleToCWEMutex.Lock()
+	defer ruleToCWEMutex.Unlock()
+	ruleToCWE[ruleID] = cweID
+}
+
+// knownRuleIDs returns the IDs of the rules mapped to a CWE, which are the rules
+// provided by gosec and the registered rules
+func knownRuleIDs() []string {
+	ruleToCWEMutex.RLock()
+	defer ruleToCWEMutex.RUnlock()
+	ids := make([]string, 0, len(ruleToCWE))
+	for id := range ruleToCWE {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ruleToCWEMutex guards ruleToCWE against the registrations
+var ruleToCWEMutex sync.RWMutex
+
 // ruleToCWE maps gosec rules to CWEs
 var ruleToCWE = map[string]string{
+	"G001": "1164",
 	"G101": "798",
 	"G102": "200",
 	"G103": "242",
@@ -88,15 +124,41 @@ var ruleToCWE = map[string]string{
 
 // Issue is returned by a gosec rule if it discovers an issue with the scanned code.
 type Issue struct {
-	Severity   Score         `json:"severity"`   // issue severity (how problematic it is)
-	Confidence Score         `json:"confidence"` // issue confidence (how sure we are we found it)
-	Cwe        *cwe.Weakness `json:"cwe"`        // Cwe associated with RuleID
-	RuleID     string        `json:"rule_id"`    // Human readable explanation
-	What       string        `json:"details"`    // Human readable explanation
-	File       string        `json:"file"`       // File name we found it in
-	Code       string        `json:"code"`       // Impacted code line
-	Line       string        `json:"line"`       // Line number in file
-	Col        string        `json:"column"`     // Column number in line
+	Severity    Score         `json:"severity"`    // issue severity (how problematic it is)
+	Confidence  Score         `json:"confidence"`  // issue confidence (how sure we are we found it)
+	Cwe         *cwe.Weakness `json:"cwe"`         // Cwe associated with RuleID
+	RuleID      string        `json:"rule_id"`     // Human readable explanation
+	What        string        `json:"details"`     // Human readable explanation
+	File        string        `json:"file"`        // File name we found it in
+	Code        string        `json:"code"`        // Impacted code line
+	Line        string        `json:"line"`        // Line number in file
+	Col         string        `json:"column"`      // Column number in line
+	Start       Position      `json:"start"`       // Position of the first character of the impacted code
+	End         Position      `json:"end"`         // Position following the last character of the impacted code
+	Fingerprint string        `json:"fingerprint"` // Stable identity of the issue
+	// Suppressions holds the #nosec directives which suppressed the issue
+	Suppressions []SuppressionInfo `json:"suppressions,omitempty" yaml:"suppressions,omitempty"`
+	// Platforms holds the platforms on which the issue was found, when several
+	// platforms are checked
+	Platforms []string `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+	// Generated is set when the issue is in a generated file
+	Generated bool `json:"generated,omitempty" yaml:"generated,omitempty"`
+}
+
+// SuppressionInfo describes a directive which suppressed an issue
+type SuppressionInfo struct {
+	Kind          string `json:"kind"`          // Kind of suppression, e.g. "inSource" for #nosec
+	Justification string `json:"justification"` // Justification given after "--" in the directive
+}
+
+// InSource is the kind of the suppressions made by #nosec directives
+const InSource = "inSource"
+
+// Position is a location in a source file
+type Position struct {
+	Line   int `json:"line"`   // Line number, starting at 1
+	Column int `json:"column"` // Column number in bytes, starting at 1
+	Offset int `json:"offset"` // Offset in bytes, starting at 0
 }
 
 // FileLocation point out the file path and line number in file
@@ -104,6 +166,34 @@ func (i Issue) FileLocation() string {
 	return fmt.Sprintf("%s:%s", i.File, i.Line)
 }
 
+// Range returns the start and the end positions of the issue. The issues which
+// were created without positions, e.g. decoded from an older report, are located
+// by parsing their line and column, hence their end column and offsets are unknown.
+func (i Issue) Range() (Position, Position, error) {
+	if i.Start.Line > 0 {
+		return i.Start, i.End, nil
+	}
+	// the line uses the "start-end" format for the issues spanning multiple lines
+	lines := strings.SplitN(i.Line, "-", 2)
+	start, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return Position{}, Position{}, fmt.Errorf("invalid line %q: %v", i.Line, err)
+	}
+	end := start
+	if len(lines) == 2 {
+		if end, err = strconv.Atoi(lines[1]); err != nil {
+			return Position{}, Position{}, fmt.Errorf("invalid line %q: %v", i.Line, err)
+		}
+	}
+	var col int
+	if i.Col != "" {
+		if col, err = strconv.Atoi(i.Col); err != nil {
+			return Position{}, Position{}, fmt.Errorf("invalid column %q: %v", i.Col, err)
+		}
+	}
+	return Position{Line: start, Column: col}, Position{Line: end}, nil
+}
+
 // MetaData is embedded in all gosec rules. The Severity, Confidence and What message
 // will be passed through to reported issues.
 type MetaData struct {
@@ -113,11 +203,35 @@ type MetaData struct {
 	What       string
 }
 
+// Describe returns the metadata of the rule embedding it
+func (m MetaData) Describe() MetaData {
+	return m
+}
+
 // MarshalJSON is used convert a Score object into a JSON representation
 func (c Score) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.String())
 }
 
+// UnmarshalJSON is used to convert a JSON representation into a Score object
+func (c *Score) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value {
+	case "HIGH":
+		*c = High
+	case "MEDIUM":
+		*c = Medium
+	case "LOW":
+		*c = Low
+	default:
+		return fmt.Errorf("invalid score %q", value)
+	}
+	return nil
+}
+
 // String converts a Score into a string
 func (c Score) String() string {
 	switch c {
@@ -132,7 +246,7 @@ func (c Score) String() string {
 }
 
 // codeSnippet extracts a code snippet based on the ast reference
-func codeSnippet(file *os.File, start int64, end int64, n ast.Node) (string, error) {
+func codeSnippet(file io.Reader, start int64, end int64, n ast.Node) (string, error) {
 	if n == nil {
 		return "", fmt.Errorf("invalid AST node provided")
 	}
@@ -169,15 +283,15 @@ func codeSnippetEndLine(node ast.Node, fobj *token.File) int64 {
 func NewIssue(ctx *Context, node ast.Node, ruleID, desc string, severity Score, confidence Score) *Issue {
 	fobj := ctx.FileSet.File(node.Pos())
 	name := fobj.Name()
-	start, end := fobj.Line(node.Pos()), fobj.Line(node.End())
-	line := strconv.Itoa(start)
-	if start != end {
-		line = fmt.Sprintf("%d-%d", start, end)
+	start, end := fobj.Position(node.Pos()), fobj.Position(node.End())
+	line := strconv.Itoa(start.Line)
+	if start.Line != end.Line {
+		line = fmt.Sprintf("%d-%d", start.Line, end.Line)
 	}
-	col := strconv.Itoa(fobj.Position(node.Pos()).Column)
+	col := strconv.Itoa(start.Column)
 
 	var code string
-	if file, err := os.Open(fobj.Name()); err == nil {
+	if file, err := openSource(ctx, fobj.Name()); err == nil {
 		defer file.Close() // #nosec
 		s := codeSnippetStartLine(node, fobj)
 		e := codeSnippetEndLine(node, fobj)
@@ -188,14 +302,115 @@ func NewIssue(ctx *Context, node ast.Node, ruleID, desc string, severity Score,
 	}
 
 	return &Issue{
-		File:       name,
-		Line:       line,
-		Col:        col,
-		RuleID:     ruleID,
-		What:       desc,
-		Confidence: confidence,
-		Severity:   severity,
-		Code:       code,
-		Cwe:        GetCweByRule(ruleID),
+		File:        name,
+		Line:        line,
+		Col:         col,
+		Start:       Position{Line: start.Line, Column: start.Column, Offset: start.Offset},
+		End:         Position{Line: end.Line, Column: end.Column, Offset: end.Offset},
+		RuleID:      ruleID,
+		What:        desc,
+		Confidence:  confidence,
+		Severity:    severity,
+		Code:        code,
+		Cwe:         GetCweByRule(ruleID),
+		Fingerprint: issueFingerprint(ctx, node, ruleID, name),
+	}
+}
+
+// issueFingerprint computes an identity of the issue which doesn't change when the
+// code around it is edited. It hashes the rule ID, the path of the file within its
+// package, the name of the enclosing declaration and the printed node, which
+// ignores the comments and the formatting.
+func issueFingerprint(ctx *Context, node ast.Node, ruleID string, file string) string {
+	var code bytes.Buffer
+	if group, ok := node.(*ast.CommentGroup); ok {
+		// the printer doesn't handle the comments on their own
+		code.WriteString(group.Text())
+	} else if err := printer.Fprint(&code, ctx.FileSet, node); err != nil {
+		code.Reset()
+		fmt.Fprintf(&code, "%T", node)
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n%s", ruleID, packageFilePath(ctx, file),
+		enclosingDecl(ctx.Root, node), strings.Join(strings.Fields(code.String()), " "))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// packageFilePath returns the name of a file within its package, prefixed by the
+// import path of the package, which is the same in every checkout of the code
+func packageFilePath(ctx *Context, file string) string {
+	if ctx.Pkg == nil {
+		return filepath.Base(file)
+	}
+	return path.Join(ctx.Pkg.Path(), filepath.Base(file))
+}
+
+// countFingerprint appends the index of the occurrence to the fingerprint of an
+// issue identical to a previous issue of the file, e.g. when the same call is made
+// twice in a function. The first occurrence keeps its fingerprint.
+func (ctx *Context) countFingerprint(issue *Issue) {
+	if issue.Fingerprint == "" || ctx.fingerprints == nil {
+		return
+	}
+	n := ctx.fingerprints[issue.Fingerprint]
+	ctx.fingerprints[issue.Fingerprint] = n + 1
+	if n > 0 {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d", issue.Fingerprint, n)))
+		issue.Fingerprint = hex.EncodeToString(sum[:])
+	}
+}
+
+// enclosingDecl returns the name of the top level declaration which contains the
+// node, e.g. "main", "(*Server).Start" or "DefaultTimeout"
+func enclosingDecl(file *ast.File, node ast.Node) string {
+	if file == nil {
+		return ""
+	}
+	for _, decl := range file.Decls {
+		if node.Pos() < decl.Pos() || node.Pos() >= decl.End() {
+			continue
+		}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				return d.Name.Name
+			}
+			return fmt.Sprintf("(%s).%s", receiverName(d.Recv.List[0].Type), d.Name.Name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if node.Pos() < spec.Pos() || node.Pos() >= spec.End() {
+					continue
+				}
+				switch sp := spec.(type) {
+				case *ast.ValueSpec:
+					names := make([]string, 0, len(sp.Names))
+					for _, name := range sp.Names {
+						names = append(names, name.Name)
+					}
+					return strings.Join(names, ",")
+				case *ast.TypeSpec:
+					return sp.Name.Name
+				case *ast.ImportSpec:
+					return "import"
+				}
+			}
+			return d.Tok.String()
+		}
+	}
+	return ""
+}
+
+// receiverName formats the type of a method receiver, e.g. "*Server"
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.ParenExpr:
+		return receiverName(t.X)
 	}
+	return ""
 }
@@ -41,9 +41,71 @@ var _ = Describe("Issue", func() {
 			Expect(issue.Code).Should(MatchRegexp(`"bar"`))
 			Expect(issue.Line).Should(Equal("2"))
 			Expect(issue.Col).Should(Equal("16"))
+			Expect(issue.Start).Should(Equal(gosec.Position{Line: 2, Column: 16, Offset: 28}))
+			Expect(issue.End).Should(Equal(gosec.Position{Line: 2, Column: 21, Offset: 33}))
 			Expect(issue.Cwe).Should(BeNil())
 		})
 
+		It("should parse the range of the issues created without positions", func() {
+			start, end, err := gosec.Issue{Line: "3-5", Col: "7"}.Range()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(start).Should(Equal(gosec.Position{Line: 3, Column: 7}))
+			Expect(end).Should(Equal(gosec.Position{Line: 5}))
+
+			_, _, err = gosec.Issue{Line: "x", Col: "7"}.Range()
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should keep the same fingerprint when the code around the issue changes", func() {
+			newIssue := func(source string, ruleID string) *gosec.Issue {
+				var target *ast.CallExpr
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("foo.go", source)
+				ctx := pkg.CreateContext("foo.go")
+				v := testutils.NewMockVisitor()
+				v.Callback = func(n ast.Node, ctx *gosec.Context) bool {
+					if node, ok := n.(*ast.CallExpr); ok {
+						target = node
+						return false
+					}
+					return true
+				}
+				v.Context = ctx
+				ast.Walk(v, ctx.Root)
+				Expect(target).ShouldNot(BeNil())
+				return gosec.NewIssue(ctx, target, ruleID, "", gosec.High, gosec.High)
+			}
+
+			issue := newIssue(`package main
+			func main(){
+				println("foo")
+			}
+			`, "TEST")
+			moved := newIssue(`package main
+			// main prints foo
+			func main(){
+
+				println( "foo" ) // foo
+			}
+			`, "TEST")
+			other := newIssue(`package main
+			func main(){
+				println("foo")
+			}
+			`, "OTHER")
+			renamed := newIssue(`package main
+			func run(){
+				println("foo")
+			}
+			`, "TEST")
+			Expect(issue.Fingerprint).ShouldNot(BeEmpty())
+			Expect(moved.Line).ShouldNot(Equal(issue.Line))
+			Expect(moved.Fingerprint).Should(Equal(issue.Fingerprint))
+			Expect(other.Fingerprint).ShouldNot(Equal(issue.Fingerprint))
+			Expect(renamed.Fingerprint).ShouldNot(Equal(issue.Fingerprint))
+		})
+
 		It("should return an error if specific context is not able to be obtained", func() {
 			Skip("Not implemented")
 		})
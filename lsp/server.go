@@ -0,0 +1,455 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lsp implements a Language Server Protocol server which publishes the
+// gosec issues of the documents opened in an editor as diagnostics.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+)
+
+// errExitWithoutShutdown is returned by Serve when the client exits without
+// shutting down the server first
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server publishes the issues of the opened documents. The package of a document
+// is checked again each time the document is opened or changed, with the content
+// of the opened documents which are not saved.
+type Server struct {
+	conn        *Conn
+	config      gosec.Config
+	rules       rules.RuleList
+	tests       bool
+	buildTags   []string
+	version     string
+	logger      *log.Logger
+	documents   map[string]*document // keyed by URI
+	initialized bool
+	shutdown    bool
+}
+
+// document is a document opened in the editor
+type document struct {
+	uri     string
+	path    string
+	version int
+	lines   []string
+	issues  []*gosec.Issue
+}
+
+// NewServer creates a server checking the documents with the given rules
+func NewServer(config gosec.Config, ruleList rules.RuleList, tests bool, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.New(os.Stderr, "[gosec]", log.LstdFlags)
+	}
+	return &Server{
+		config:    config,
+		rules:     ruleList,
+		tests:     tests,
+		logger:    logger,
+		documents: make(map[string]*document),
+	}
+}
+
+// SetBuildTags sets the build tags used to load the packages
+func (s *Server) SetBuildTags(buildTags []string) {
+	s.buildTags = buildTags
+}
+
+// SetVersion sets the version of gosec reported to the client
+func (s *Server) SetVersion(version string) {
+	s.version = version
+}
+
+// Serve reads the messages of the client from r and writes the responses and the
+// diagnostics to w, until the client exits
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = NewConn(r, w)
+	for {
+		msg, err := s.conn.Read()
+		if err != nil {
+			if err == io.EOF && s.shutdown {
+				return nil
+			}
+			return err
+		}
+		if msg.Method == methodExit {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or a notification
+func (s *Server) handle(msg *Message) error {
+	if msg.ID == nil {
+		if err := s.notify(msg.Method, msg.Params); err != nil {
+			s.logger.Printf("LSP %s: %v", msg.Method, err)
+		}
+		return nil
+	}
+	result, rerr := s.call(msg.Method, msg.Params)
+	return s.conn.Reply(msg.ID, result, rerr)
+}
+
+// call handles a request and returns its result
+func (s *Server) call(method string, params json.RawMessage) (interface{}, *ResponseError) {
+	switch {
+	case s.shutdown:
+		return nil, &ResponseError{Code: errorCodeInvalidRequest, Message: "the server is shut down"}
+	case !s.initialized && method != methodInitialize:
+		return nil, &ResponseError{Code: errorCodeServerNotStarted, Message: "the server is not initialized"}
+	}
+
+	switch method {
+	case methodInitialize:
+		s.initialized = true
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   TextDocumentSyncOptions{OpenClose: true, Change: textDocumentSyncKindFull},
+				CodeActionProvider: true,
+				HoverProvider:      true,
+			},
+			ServerInfo: ServerInfo{Name: "gosec", Version: s.version},
+		}, nil
+	case methodShutdown:
+		s.shutdown = true
+		return nil, nil
+	case methodCodeAction:
+		var p CodeActionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.codeActions(&p), nil
+	case methodHover:
+		var p TextDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.hover(&p), nil
+	}
+	return nil, &ResponseError{Code: errorCodeMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+}
+
+func invalidParams(err error) *ResponseError {
+	return &ResponseError{Code: errorCodeInvalidParams, Message: err.Error()}
+}
+
+// notify handles a notification. The unknown notifications are ignored.
+func (s *Server) notify(method string, params json.RawMessage) error {
+	switch method {
+	case methodDidOpen:
+		var p DidOpenTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		path, err := uriToPath(p.TextDocument.URI)
+		if err != nil {
+			return err
+		}
+		doc := &document{uri: p.TextDocument.URI, path: path, version: p.TextDocument.Version}
+		doc.setText(p.TextDocument.Text)
+		s.documents[doc.uri] = doc
+		return s.check(filepath.Dir(path))
+	case methodDidChange:
+		var p DidChangeTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		doc, ok := s.documents[p.TextDocument.URI]
+		if !ok || len(p.ContentChanges) == 0 {
+			return nil
+		}
+		// the documents are fully synchronized, hence the last change holds the content
+		doc.version = p.TextDocument.Version
+		doc.setText(p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return s.check(filepath.Dir(doc.path))
+	case methodDidClose:
+		var p DidCloseTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return err
+		}
+		delete(s.documents, p.TextDocument.URI)
+		return s.conn.Notify(methodPublishDiagnostics, &PublishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	}
+	return nil
+}
+
+// check analyzes the package in the directory, with the content of the opened
+// documents, and publishes the diagnostics of the documents of the package
+func (s *Server) check(dir string) error {
+	overlay := make(map[string][]byte, len(s.documents))
+	for _, doc := range s.documents {
+		overlay[doc.path] = []byte(strings.Join(doc.lines, "\n"))
+	}
+	report, err := gosec.Scan(context.Background(), gosec.Options{
+		Patterns:  []string{dir},
+		Rules:     s.rules.Builders(),
+		Config:    s.config,
+		Tests:     s.tests,
+		BuildTags: s.buildTags,
+		Overlay:   overlay,
+		Logger:    s.logger,
+	})
+	if err != nil {
+		return err
+	}
+	byFile := make(map[string][]*gosec.Issue)
+	for _, issue := range report.Issues {
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+
+	uris := make([]string, 0, len(s.documents))
+	for uri, doc := range s.documents {
+		if filepath.Dir(doc.path) == dir {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		doc := s.documents[uri]
+		doc.issues = byFile[doc.path]
+		version := doc.version
+		params := &PublishDiagnosticsParams{URI: uri, Version: &version, Diagnostics: []Diagnostic{}}
+		for _, issue := range doc.issues {
+			params.Diagnostics = append(params.Diagnostics, doc.diagnostic(issue))
+		}
+		if err := s.conn.Notify(methodPublishDiagnostics, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nosecComment matches the #nosec directive of a line
+var nosecComment = regexp.MustCompile(`#nosec\b`)
+
+// codeActions proposes to suppress the issues of the diagnostics with a #nosec
+// comment at the end of their first line
+func (s *Server) codeActions(p *CodeActionParams) []CodeAction {
+	actions := []CodeAction{}
+	doc, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return actions
+	}
+	seen := make(map[string]bool)
+	for _, diag := range p.Context.Diagnostics {
+		line := diag.Range.Start.Line
+		key := fmt.Sprintf("%d:%s", line, diag.Code)
+		if diag.Source != diagnosticSource || diag.Code == gosec.StaleNosecRuleID || seen[key] || line >= len(doc.lines) {
+			continue
+		}
+		seen[key] = true
+
+		text := strings.TrimSuffix(doc.lines[line], "\r")
+		edit := TextEdit{NewText: fmt.Sprintf(" // #nosec %s -- reason", diag.Code)}
+		if loc := nosecComment.FindStringIndex(text); loc != nil {
+			// add the rule to the existing directive
+			edit.NewText = " " + diag.Code
+			edit.Range.Start = Position{Line: line, Character: utf16Len(text[:loc[1]])}
+		} else {
+			edit.Range.Start = Position{Line: line, Character: utf16Len(text)}
+		}
+		edit.Range.End = edit.Range.Start
+		actions = append(actions, CodeAction{
+			Title:       fmt.Sprintf("Suppress %s with a #nosec comment", diag.Code),
+			Kind:        codeActionKindQuickFix,
+			Diagnostics: []Diagnostic{diag},
+			Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{doc.uri: {edit}}},
+		})
+	}
+	return actions
+}
+
+// hover describes the rules and the weaknesses of the issues at the position
+func (s *Server) hover(p *TextDocumentPositionParams) *Hover {
+	doc, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	var parts []string
+	var hovered *Range
+	for _, issue := range doc.issues {
+		diag := doc.diagnostic(issue)
+		if !diag.Range.contains(p.Position) {
+			continue
+		}
+		if hovered == nil {
+			hovered = &diag.Range
+		}
+		parts = append(parts, s.describe(issue))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: markupKindMarkdown, Value: strings.Join(parts, "\n\n---\n\n")},
+		Range:    hovered,
+	}
+}
+
+// describe formats in markdown the rule and the weakness of an issue
+func (s *Server) describe(issue *gosec.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**", issue.RuleID)
+	if rule, ok := s.rules[issue.RuleID]; ok {
+		fmt.Fprintf(&b, ": %s", rule.Description)
+	}
+	fmt.Fprintf(&b, "\n\n%s (Severity: %s, Confidence: %s)", issue.What, issue.Severity, issue.Confidence)
+	if issue.Cwe != nil {
+		fmt.Fprintf(&b, "\n\n[%s](%s): %s", issue.Cwe.SprintID(), issue.Cwe.SprintURL(), issue.Cwe.Name)
+		if issue.Cwe.Description != "" {
+			fmt.Fprintf(&b, "\n\n%s", issue.Cwe.Description)
+		}
+	}
+	return b.String()
+}
+
+func (d *document) setText(text string) {
+	d.lines = strings.Split(text, "\n")
+}
+
+// diagnostic converts an issue of the document into a diagnostic
+func (d *document) diagnostic(issue *gosec.Issue) Diagnostic {
+	diag := Diagnostic{
+		Severity: severity(issue.Severity),
+		Code:     issue.RuleID,
+		Source:   diagnosticSource,
+		Message:  issue.What,
+	}
+	if issue.Cwe != nil {
+		diag.Message = fmt.Sprintf("%s (%s)", issue.What, issue.Cwe.SprintID())
+		diag.CodeDescription = &CodeDescription{Href: issue.Cwe.SprintURL()}
+	}
+	start, end, err := issue.Range()
+	if err != nil {
+		return diag
+	}
+	diag.Range.Start = d.position(start.Line, start.Column)
+	if end.Line == 0 || end.Column == 0 {
+		// the end of the issue is unknown, the range covers the rest of the line
+		diag.Range.End = d.position(start.Line, len(d.line(start.Line))+1)
+	} else {
+		diag.Range.End = d.position(end.Line, end.Column)
+	}
+	return diag
+}
+
+// line returns the text of a 1-based line
+func (d *document) line(line int) string {
+	if line < 1 || line > len(d.lines) {
+		return ""
+	}
+	return strings.TrimSuffix(d.lines[line-1], "\r")
+}
+
+// position converts a 1-based line and byte column into a LSP position
+func (d *document) position(line, column int) Position {
+	if line < 1 {
+		return Position{}
+	}
+	text := d.line(line)
+	if column < 1 {
+		column = 1
+	}
+	if column-1 > len(text) {
+		column = len(text) + 1
+	}
+	return Position{Line: line - 1, Character: utf16Len(text[:column-1])}
+}
+
+// utf16Len returns the length of the text in UTF-16 code units
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// contains checks if the position is in the range. An empty range contains its
+// start position.
+func (r Range) contains(p Position) bool {
+	before := func(a, b Position) bool {
+		return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+	}
+	if before(p, r.Start) {
+		return false
+	}
+	return before(p, r.End) || p == r.Start
+}
+
+// severity maps the severity of an issue to the severity of a diagnostic
+func severity(score gosec.Score) DiagnosticSeverity {
+	switch score {
+	case gosec.High:
+		return SeverityError
+	case gosec.Medium:
+		return SeverityWarning
+	}
+	return SeverityInformation
+}
+
+// windowsDrive matches the path of a file URI starting with a drive letter
+var windowsDrive = regexp.MustCompile(`^/[A-Za-z]:`)
+
+// uriToPath converts a file URI into a file path
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI %q, expected a file URI", uri)
+	}
+	path := u.Path
+	if windowsDrive.MatchString(path) {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// PathToURI converts a file path into a file URI
+func PathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
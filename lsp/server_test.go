@@ -0,0 +1,203 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/lsp"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+// client drives a server through pipes, as an editor would do
+type client struct {
+	conn          *lsp.Conn
+	id            int
+	notifications []*lsp.Message
+	done          chan error
+	closers       []io.Closer
+}
+
+func newClient(server *lsp.Server) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{
+		conn:    lsp.NewConn(clientIn, clientOut),
+		done:    make(chan error, 1),
+		closers: []io.Closer{clientOut, serverOut},
+	}
+	go func() {
+		err := server.Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+// call sends a request and decodes the result of its response, while keeping
+// the notifications received in between
+func (c *client) call(method string, params interface{}, result interface{}) *lsp.ResponseError {
+	c.id++
+	Expect(c.conn.Call(c.id, method, params)).Should(Succeed())
+	for {
+		msg, err := c.conn.Read()
+		Expect(err).ShouldNot(HaveOccurred())
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		Expect(string(*msg.ID)).To(Equal(strconv.Itoa(c.id)))
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			Expect(json.Unmarshal(msg.Result, result)).Should(Succeed())
+		}
+		return nil
+	}
+}
+
+// diagnostics sends a notification and waits for the diagnostics it publishes
+func (c *client) diagnostics(method string, params interface{}) *lsp.PublishDiagnosticsParams {
+	Expect(c.conn.Notify(method, params)).Should(Succeed())
+	msg, err := c.conn.Read()
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(msg.Method).To(Equal("textDocument/publishDiagnostics"))
+	var diagnostics lsp.PublishDiagnosticsParams
+	Expect(json.Unmarshal(msg.Params, &diagnostics)).Should(Succeed())
+	return &diagnostics
+}
+
+func (c *client) close() {
+	for _, closer := range c.closers {
+		closer.Close()
+	}
+}
+
+var _ = Describe("Server", func() {
+	const source = "package main\n\nimport \"crypto/md5\"\n\nfunc main() {\n\tprintln(md5.Sum(nil)[0])\n}\n"
+
+	var (
+		pkg    *testutils.TestPackage
+		c      *client
+		path   string
+		uri    string
+		opened *lsp.PublishDiagnosticsParams
+	)
+
+	BeforeEach(func() {
+		pkg = testutils.NewTestPackage()
+		pkg.AddFile("main.go", `
+			package main
+			func main() {
+				println("saved")
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+		path = filepath.Join(pkg.Path, "main.go")
+		uri = lsp.PathToURI(path)
+
+		logger, _ := testutils.NewLogger()
+		ruleList := rules.Generate(rules.NewRuleFilter(false, "G401"))
+		server := lsp.NewServer(gosec.NewConfig(), ruleList, false, logger)
+		c = newClient(server)
+
+		var result lsp.InitializeResult
+		Expect(c.call("initialize", map[string]interface{}{}, &result)).To(BeNil())
+		Expect(result.ServerInfo.Name).To(Equal("gosec"))
+		Expect(result.Capabilities.HoverProvider).To(BeTrue())
+		Expect(c.conn.Notify("initialized", struct{}{})).Should(Succeed())
+
+		opened = c.diagnostics("textDocument/didOpen", &lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: source},
+		})
+	})
+
+	AfterEach(func() {
+		c.close()
+		pkg.Close()
+	})
+
+	It("should publish the issues of the unsaved document", func() {
+		Expect(opened.URI).To(Equal(uri))
+		Expect(*opened.Version).To(Equal(1))
+		Expect(opened.Diagnostics).To(HaveLen(1))
+		diagnostic := opened.Diagnostics[0]
+		Expect(diagnostic.Code).To(Equal("G401"))
+		Expect(diagnostic.Source).To(Equal("gosec"))
+		Expect(diagnostic.Severity).To(Equal(lsp.SeverityWarning))
+		Expect(diagnostic.Message).To(ContainSubstring("Use of weak cryptographic primitive"))
+		Expect(diagnostic.CodeDescription.Href).To(ContainSubstring("cwe.mitre.org"))
+		Expect(diagnostic.Range).To(Equal(lsp.Range{
+			Start: lsp.Position{Line: 5, Character: 9},
+			End:   lsp.Position{Line: 5, Character: 21},
+		}))
+	})
+
+	It("should clear the diagnostics when the issue is fixed", func() {
+		fixed := strings.Replace(source, "md5", "sha256", -1)
+		diagnostics := c.diagnostics("textDocument/didChange", &lsp.DidChangeTextDocumentParams{
+			TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: fixed}},
+		})
+		Expect(*diagnostics.Version).To(Equal(2))
+		Expect(diagnostics.Diagnostics).To(BeEmpty())
+
+		diagnostics = c.diagnostics("textDocument/didClose", &lsp.DidCloseTextDocumentParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		})
+		Expect(diagnostics.URI).To(Equal(uri))
+		Expect(diagnostics.Diagnostics).To(BeEmpty())
+	})
+
+	It("should propose to suppress the issue with a #nosec comment", func() {
+		var actions []lsp.CodeAction
+		Expect(c.call("textDocument/codeAction", &lsp.CodeActionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			Range:        opened.Diagnostics[0].Range,
+			Context:      lsp.CodeActionContext{Diagnostics: opened.Diagnostics},
+		}, &actions)).To(BeNil())
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].Kind).To(Equal("quickfix"))
+		edits := actions[0].Edit.Changes[uri]
+		Expect(edits).To(Equal([]lsp.TextEdit{{
+			Range:   lsp.Range{Start: lsp.Position{Line: 5, Character: 25}, End: lsp.Position{Line: 5, Character: 25}},
+			NewText: " // #nosec G401 -- reason",
+		}}))
+	})
+
+	It("should describe the rule and the weakness of the issue on hover", func() {
+		var hover lsp.Hover
+		Expect(c.call("textDocument/hover", &lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			Position:     lsp.Position{Line: 5, Character: 12},
+		}, &hover)).To(BeNil())
+		Expect(hover.Contents.Kind).To(Equal("markdown"))
+		Expect(hover.Contents.Value).To(ContainSubstring("**G401**: Detect the usage of DES, RC4, MD5 or SHA1"))
+		Expect(hover.Contents.Value).To(ContainSubstring("[CWE-326]"))
+		Expect(*hover.Range).To(Equal(opened.Diagnostics[0].Range))
+
+		var none *lsp.Hover
+		Expect(c.call("textDocument/hover", &lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			Position:     lsp.Position{Line: 0, Character: 0},
+		}, &none)).To(BeNil())
+		Expect(none).To(BeNil())
+	})
+
+	It("should reject the unknown requests and exit after the shutdown", func() {
+		rerr := c.call("workspace/symbol", struct{}{}, nil)
+		Expect(rerr).ShouldNot(BeNil())
+		Expect(rerr.Code).To(Equal(-32601))
+
+		Expect(c.call("shutdown", nil, nil)).To(BeNil())
+		Expect(c.conn.Notify("exit", nil)).Should(Succeed())
+		Expect(<-c.done).Should(Succeed())
+		Expect(c.notifications).To(BeEmpty())
+	})
+})
@@ -0,0 +1,344 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// packageJob is the analysis of a package directory. The packages of the patterns
+// which belong to a module are loaded together, while the directories outside of
+// a module are loaded on their own by the workers, before any job is checked.
+type packageJob struct {
+	path   string
+	loaded bool                // the packages were loaded, or the results cached
+	pkgs   []*packages.Package // the packages found in the directory
+	key    string              // the cache key, empty when not cached
+	result *packageResult      // the cached results
+}
+
+// moduleGroup holds the patterns loaded from the same root directory, and the
+// jobs of the package directories matched by the patterns once they are loaded
+type moduleGroup struct {
+	root     string
+	patterns []string
+	jobs     []*packageJob
+}
+
+// moduleRoot returns the directory from which a package directory is loaded
+// along with the other packages of its module: the directory of the go.work
+// file when the module is part of a workspace, or the directory of its go.mod
+// file. It's empty when the directory is not in a module.
+func moduleRoot(dir string) string {
+	if os.Getenv("GO111MODULE") == "off" {
+		return ""
+	}
+	modDir := findUp(dir, "go.mod")
+	if modDir == "" {
+		return ""
+	}
+	switch work := os.Getenv("GOWORK"); work {
+	case "off":
+		return modDir
+	case "":
+		if workDir := findUp(modDir, "go.work"); workDir != "" {
+			return workDir
+		}
+		return modDir
+	default:
+		return filepath.Dir(work)
+	}
+}
+
+// findUp returns the closest directory containing the file, starting from dir
+// and walking up to the root of the file system
+func findUp(dir string, file string) string {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, file)); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// groupJobs groups the patterns which belong to a module by root directory, and
+// creates the jobs of the other patterns, whose package directories are found by
+// walking the file system. A pattern ending with "..." matches the packages of
+// the sub-directories as well, including the ones of the nested modules, which
+// are loaded from their own root directory.
+func groupJobs(patterns []string, excludes []*regexp.Regexp) ([]*packageJob, []*moduleGroup, error) {
+	var jobs []*packageJob
+	var groups []*moduleGroup
+	byRoot := make(map[string]*moduleGroup)
+	addPattern := func(root string, pattern string) {
+		group, ok := byRoot[root]
+		if !ok {
+			group = &moduleGroup{root: root}
+			byRoot[root] = group
+			groups = append(groups, group)
+		}
+		for _, p := range group.patterns {
+			if p == pattern {
+				return
+			}
+		}
+		group.patterns = append(group.patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		recursive := strings.HasSuffix(pattern, "...")
+		root := ""
+		abspath, err := GetPkgAbsPath(strings.TrimSuffix(pattern, "..."))
+		if err == nil {
+			root = moduleRoot(abspath)
+		}
+		if root == "" {
+			paths, err := PackagePaths(pattern, excludes)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, path := range paths {
+				jobs = append(jobs, &packageJob{path: path})
+			}
+			continue
+		}
+		addPattern(root, modulePattern(root, abspath, recursive))
+		if !recursive {
+			continue
+		}
+		for _, dir := range nestedModules(abspath, excludes) {
+			// the modules of the same workspace are already matched by the pattern
+			if nestedRoot := moduleRoot(dir); nestedRoot != root {
+				addPattern(nestedRoot, modulePattern(nestedRoot, dir, true))
+			}
+		}
+	}
+	return jobs, groups, nil
+}
+
+// nestedModules returns the directories below dir which hold a go.mod file, and
+// whose packages are therefore not matched by the recursive pattern of dir. As
+// the go command, it skips the directories starting with "." or "_", and the
+// testdata and vendor directories.
+func nestedModules(dir string, excludes []*regexp.Regexp) []string {
+	var dirs []string
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || path == dir {
+			return nil
+		}
+		name := info.Name()
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" ||
+			isExcluded(relativeDir(path), excludes) {
+			return filepath.SkipDir
+		}
+		if info, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && !info.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// loadModule loads with a single call the packages matched by the patterns of
+// the group, and creates a job per package directory. The packages are loaded
+// from the root of the module, so that the replace directives and the workspace
+// are taken into account. When the cache is enabled, nothing is loaded if all
+// the packages are cached, and otherwise only the packages which are not cached
+// are checked. They are still loaded along with the cached ones, so that the
+// calls to the functions of the other packages are resolved as without cache.
+func (gosec *Analyzer) loadModule(buildTags []string, group *moduleGroup) error {
+	gosec.logger.Println("Import module:", group.root)
+	if gosec.cache != nil {
+		if jobs, ok := gosec.cachedJobs(buildTags, group); ok {
+			if len(jobs) == 0 {
+				return nil
+			}
+			pkgs, err := gosec.loadPatterns(LoadMode, buildTags, group.root, group.patterns)
+			if err != nil {
+				return err
+			}
+			gosec.resolved.addPackages(pkgs)
+			assignPackages(jobs, pkgs)
+			return nil
+		}
+	}
+
+	pkgs, err := gosec.loadPatterns(LoadMode, buildTags, group.root, group.patterns)
+	if err != nil {
+		return err
+	}
+	gosec.resolved.addPackages(pkgs)
+	group.jobs = gosec.moduleJobs(pkgs)
+	assignPackages(group.jobs, pkgs)
+	return nil
+}
+
+// loadPatterns loads the packages matched by the patterns from the root directory
+func (gosec *Analyzer) loadPatterns(mode packages.LoadMode, buildTags []string, root string, patterns []string) ([]*packages.Package, error) {
+	start := time.Now()
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       mode,
+		Dir:        root,
+		BuildFlags: buildTagsFlags(buildTags),
+		Tests:      gosec.tests,
+		Env:        gosec.loadEnv(),
+		Overlay:    gosec.overlay,
+		Context:    gosec.ctx,
+	}, patterns...)
+	if gosec.profileRules && mode == LoadMode {
+		gosec.profile().addPackage(root, time.Since(start))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading module %s: %w", root, err)
+	}
+	return pkgs, nil
+}
+
+// cachedJobs creates the jobs of the group from the packages loaded without their
+// syntax, sets their cache key and cached results, and returns the jobs which are
+// not cached. It returns false when the packages couldn't be loaded.
+func (gosec *Analyzer) cachedJobs(buildTags []string, group *moduleGroup) ([]*packageJob, bool) {
+	pkgs, err := gosec.loadPatterns(cacheLoadMode, buildTags, group.root, group.patterns)
+	if err != nil {
+		gosec.logger.Printf("Not caching %s: %v", group.root, err)
+		return nil, false
+	}
+	group.jobs = gosec.moduleJobs(pkgs)
+	assignPackages(group.jobs, pkgs)
+
+	var missing []*packageJob
+	for _, job := range group.jobs {
+		key, err := gosec.hashPackages(buildTags, job.pkgs)
+		job.pkgs = nil
+		if err != nil {
+			gosec.logger.Printf("Not caching %s: %v", job.path, err)
+			missing = append(missing, job)
+			continue
+		}
+		if result, ok := gosec.cache.get(key); ok {
+			gosec.logger.Println("Cached directory:", job.path)
+			job.result = result
+			continue
+		}
+		job.key = key
+		missing = append(missing, job)
+	}
+	return missing, true
+}
+
+// moduleJobs creates a job per directory of the packages, except for the
+// excluded directories, in the order of the directories
+func (gosec *Analyzer) moduleJobs(pkgs []*packages.Package) []*packageJob {
+	seen := make(map[string]bool)
+	var jobs []*packageJob
+	for _, pkg := range pkgs {
+		dir := packageDir(pkg)
+		if dir == "" || seen[dir] || isExcluded(relativeDir(dir), gosec.excludes) {
+			continue
+		}
+		seen[dir] = true
+		jobs = append(jobs, &packageJob{path: dir})
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].path < jobs[j].path
+	})
+	return jobs
+}
+
+// assignPackages dispatches the loaded packages to the jobs of their directory.
+// When the tests are loaded, a package is replaced by its test variant, which
+// holds the same files along with the test files.
+func assignPackages(jobs []*packageJob, pkgs []*packages.Package) {
+	byDir := make(map[string]*packageJob, len(jobs))
+	for _, job := range jobs {
+		byDir[job.path] = job
+		job.loaded = true
+	}
+	variants := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test]") && !strings.HasSuffix(pkg.PkgPath, "_test") {
+			variants[pkg.PkgPath] = true
+		}
+	}
+	for _, pkg := range pkgs {
+		if variants[pkg.ID] {
+			continue
+		}
+		job, ok := byDir[packageDir(pkg)]
+		if !ok {
+			continue
+		}
+		job.pkgs = append(job.pkgs, pkg)
+	}
+}
+
+// packageDir returns the directory of the files of a package, which is empty
+// when the package has no file, e.g. when the directory doesn't exist
+func packageDir(pkg *packages.Package) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		for _, file := range files {
+			if filepath.Ext(file) == ".go" {
+				return filepath.Dir(file)
+			}
+		}
+	}
+	return ""
+}
+
+// relativeDir returns the directory relative to the working directory when it's
+// below it, as the paths walked from a relative pattern, or else the directory
+func relativeDir(dir string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
+	}
+	return rel
+}
+
+// modulePattern converts an absolute package directory into a pattern relative
+// to the root of its module, which matches the sub-directories when recursive
+func modulePattern(root string, dir string, recursive bool) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		rel = dir
+	} else if rel != "." {
+		rel = "./" + filepath.ToSlash(rel)
+	}
+	if recursive {
+		return strings.TrimSuffix(rel, "/") + "/..."
+	}
+	return rel
+}
+
+// buildTagsFlags converts the build tags into the flags of the go command
+func buildTagsFlags(buildTags []string) []string {
+	if len(buildTags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(buildTags, ",")}
+}
@@ -0,0 +1,106 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// StaleNosecRuleID is the rule ID of the issues reported for the #nosec directives
+// which don't suppress any issue
+const StaleNosecRuleID = "G001"
+
+// nosecDirective is a #nosec comment found while walking a file. It records the
+// rules which matched in the suppressed code, in order to report the directives
+// which became useless.
+type nosecDirective struct {
+	group       *ast.CommentGroup
+	rules       []string // empty when all the rules are suppressed
+	suppression SuppressionInfo
+	used        map[string]bool
+}
+
+// covers checks if the directive suppresses the rule
+func (d *nosecDirective) covers(ruleID string) bool {
+	if len(d.rules) == 0 {
+		return true
+	}
+	for _, id := range d.rules {
+		if id == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// markUsedNosec records that the directives enclosing the current node suppressed
+// an issue of the rule
+func (gosec *Analyzer) markUsedNosec(ruleID string) {
+	for _, nosec := range gosec.context.nosecs {
+		if nosec == nil || !nosec.covers(ruleID) {
+			continue
+		}
+		if nosec.used == nil {
+			nosec.used = make(map[string]bool)
+		}
+		nosec.used[ruleID] = true
+	}
+}
+
+// reportStaleNosec reports the directives of the current file which suppressed no
+// issue. The rule IDs listed in a directive are reported one by one, except the IDs
+// of the rules which are not loaded, since they were not evaluated.
+func (gosec *Analyzer) reportStaleNosec() {
+	if !gosec.staleNosec {
+		return
+	}
+	// a directive for all the rules might suppress the issues of the rules which
+	// are not loaded, e.g. when the rules are narrowed by -include or -exclude
+	allLoaded := gosec.loadsKnownRules()
+	for _, nosec := range gosec.directives {
+		if len(nosec.rules) == 0 {
+			if len(nosec.used) == 0 && allLoaded {
+				gosec.addStaleNosec(nosec, "#nosec directive does not suppress any issue")
+			}
+			continue
+		}
+		reported := make(map[string]bool)
+		for _, id := range nosec.rules {
+			if _, loaded := gosec.builders[id]; !loaded || nosec.used[id] || reported[id] {
+				continue
+			}
+			reported[id] = true
+			gosec.addStaleNosec(nosec, fmt.Sprintf("#nosec directive does not suppress any %s issue", id))
+		}
+	}
+}
+
+// loadsKnownRules checks whether all the rules known by their CWE are loaded
+func (gosec *Analyzer) loadsKnownRules() bool {
+	for _, id := range knownRuleIDs() {
+		if _, loaded := gosec.builders[id]; !loaded && id != StaleNosecRuleID {
+			return false
+		}
+	}
+	return true
+}
+
+func (gosec *Analyzer) addStaleNosec(nosec *nosecDirective, what string) {
+	issue := NewIssue(gosec.context, nosec.group, StaleNosecRuleID, what, Low, High)
+	if gosec.context.generated != "" {
+		markGenerated(issue, gosec.context.generated)
+	}
+	gosec.issues = append(gosec.issues, issue)
+	gosec.stats.NumFound++
+}
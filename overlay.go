@@ -0,0 +1,62 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// openSource opens a source file, or reads its content from the overlay of the
+// context when it's not saved
+func openSource(ctx *Context, name string) (io.ReadCloser, error) {
+	if content, ok := ctx.overlay[name]; ok {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	return os.Open(name) // #nosec G304
+}
+
+// overlayFiles returns the Go files of the overlay which are in the package
+// directory, but not in the files found on disk
+func (gosec *Analyzer) overlayFiles(pkgPath string, packageFiles []string) []string {
+	if len(gosec.overlay) == 0 {
+		return nil
+	}
+	dir, err := filepath.Abs(pkgPath)
+	if err != nil {
+		return nil
+	}
+	known := make(map[string]bool, len(packageFiles))
+	for _, file := range packageFiles {
+		if abs, err := filepath.Abs(file); err == nil {
+			known[abs] = true
+		}
+	}
+	var files []string
+	for file := range gosec.overlay {
+		if filepath.Dir(file) != dir || filepath.Ext(file) != ".go" || known[file] {
+			continue
+		}
+		if strings.HasSuffix(file, "_test.go") && !gosec.tests {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
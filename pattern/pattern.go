@@ -0,0 +1,466 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pattern compiles the code patterns written as Go snippets, and matches
+// them structurally against the syntax tree of a package. A pattern is a Go
+// expression, statement or declaration in which:
+//
+//   - $X is a metavariable matching any node, the nodes matched by the same
+//     metavariable must be identical, and $_ matches any node without binding it
+//   - $...X is a metavariable matching any sequence of arguments, elements,
+//     statements or parameters
+//   - ... matches any sequence, like an anonymous $...X
+//
+// For instance, $DB.Exec(fmt.Sprintf($FMT, $...ARGS)) matches the calls to Exec
+// with a query formatted by fmt.Sprintf, and http.Cookie{..., Secure: false, ...}
+// matches the cookies explicitly marked as insecure.
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The metavariables and the ellipses are rewritten into identifiers to parse the
+// patterns as Go code.
+const (
+	metaPrefix     = "__gosec_mv_"
+	metaListPrefix = "__gosec_mvs_"
+	anyName        = "__gosec_any"
+	ellipsisName   = "__gosec_ellipsis"
+)
+
+// Constraint restricts the nodes bound to a metavariable
+type Constraint func(n ast.Node, info *types.Info) bool
+
+// Bindings holds the nodes bound to the metavariables by a match, keyed by name
+// (e.g. "$DB"). A metavariable matching a single node is bound to one node.
+type Bindings map[string][]ast.Node
+
+// Pattern is a compiled code pattern
+type Pattern struct {
+	src           string
+	root          ast.Node
+	metavariables map[string]bool
+	constraints   map[string][]Constraint
+}
+
+// Compile parses a code pattern
+func Compile(src string) (*Pattern, error) {
+	rewritten, metavariables, err := rewrite(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", src, err)
+	}
+	root, err := parse(rewritten)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", src, err)
+	}
+	if id, ok := root.(*ast.Ident); ok && (isWildcard(id) || strings.HasPrefix(id.Name, metaPrefix)) {
+		return nil, fmt.Errorf("invalid pattern %q: it matches any node", src)
+	}
+	return &Pattern{
+		src:           src,
+		root:          root,
+		metavariables: metavariables,
+		constraints:   make(map[string][]Constraint),
+	}, nil
+}
+
+// String returns the source of the pattern
+func (p *Pattern) String() string {
+	return p.src
+}
+
+// Node returns a nil node of the type of the nodes which can match the pattern,
+// e.g. (*ast.CallExpr)(nil), to register a rule for them.
+func (p *Pattern) Node() ast.Node {
+	return reflect.Zero(reflect.TypeOf(p.root)).Interface().(ast.Node)
+}
+
+// Metavariables returns the sorted names of the metavariables of the pattern
+func (p *Pattern) Metavariables() []string {
+	names := make([]string, 0, len(p.metavariables))
+	for name := range p.metavariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasMetavariable checks whether the pattern has the metavariable, written $X or $...X
+func (p *Pattern) HasMetavariable(name string) bool {
+	return p.metavariables[metavariableName(name)]
+}
+
+// Where adds a constraint on the nodes bound to the metavariable, written $X or $...X
+func (p *Pattern) Where(name string, c Constraint) {
+	name = metavariableName(name)
+	p.constraints[name] = append(p.constraints[name], c)
+}
+
+// Match checks whether the node matches the pattern, and returns the bindings of
+// the metavariables. The given bindings, which can be nil, are the nodes that the
+// metavariables must match, e.g. the bindings of a previous match.
+func (p *Pattern) Match(n ast.Node, info *types.Info, bindings Bindings) (Bindings, bool) {
+	if n == nil {
+		return nil, false
+	}
+	m := &matcher{pattern: p, info: info, bindings: make(Bindings, len(bindings))}
+	for name, nodes := range bindings {
+		m.bindings[name] = nodes
+	}
+	if !m.match(reflect.ValueOf(p.root), reflect.ValueOf(n)) {
+		return nil, false
+	}
+	return m.bindings, true
+}
+
+// TypeIs returns a constraint satisfied by the expressions of the given type,
+// written as by types.TypeString, e.g. "*database/sql.DB"
+func TypeIs(typ string) Constraint {
+	return func(n ast.Node, info *types.Info) bool {
+		expr, ok := n.(ast.Expr)
+		if !ok || info == nil {
+			return false
+		}
+		t := info.TypeOf(expr)
+		return t != nil && types.TypeString(t, nil) == typ
+	}
+}
+
+// metavariableName normalizes the name of a metavariable, $...X being named $X
+func metavariableName(name string) string {
+	return "$" + strings.TrimPrefix(strings.TrimPrefix(name, "$"), "...")
+}
+
+// rewrite replaces the metavariables and the ellipses of a pattern by identifiers,
+// and returns the names of the metavariables
+func rewrite(src string) (string, map[string]bool, error) {
+	var b strings.Builder
+	metavariables := make(map[string]bool)
+	prev := byte('\n') // last character written, which is not a space or a tab
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end := literalEnd(src, i)
+			b.WriteString(src[i:end])
+			i, prev = end, c
+			continue
+		case c == '$':
+			list := strings.HasPrefix(src[i+1:], "...")
+			start := i + 1
+			if list {
+				start += 3
+			}
+			end := start
+			for end < len(src) && isIdentChar(src[end], end == start) {
+				end++
+			}
+			name := src[start:end]
+			if name == "" {
+				return "", nil, fmt.Errorf("metavariable without name at offset %d", i)
+			}
+			switch {
+			case name == "_" && list:
+				b.WriteString(ellipsisName)
+			case name == "_":
+				b.WriteString(anyName)
+			case list:
+				b.WriteString(metaListPrefix + name)
+				metavariables["$"+name] = true
+			default:
+				b.WriteString(metaPrefix + name)
+				metavariables["$"+name] = true
+			}
+			i, prev = end, 'x'
+			continue
+		case strings.HasPrefix(src[i:], "...") && strings.IndexByte("(,{;\n", prev) >= 0:
+			b.WriteString(ellipsisName)
+			i, prev = i+3, 'x'
+			continue
+		}
+		b.WriteByte(c)
+		if c != ' ' && c != '\t' {
+			prev = c
+		}
+		i++
+	}
+	return b.String(), metavariables, nil
+}
+
+// literalEnd returns the offset following the string or rune literal starting at i
+func literalEnd(src string, i int) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case src[j] == '\\' && quote != '`':
+			j++
+		case src[j] == quote:
+			return j + 1
+		}
+	}
+	return len(src)
+}
+
+func isIdentChar(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+// parse parses a rewritten pattern as an expression, a declaration or a statement
+func parse(src string) (ast.Node, error) {
+	if expr, err := parser.ParseExpr(src); err == nil {
+		return expr, nil
+	}
+	fset := token.NewFileSet()
+	if file, err := parser.ParseFile(fset, "", "package p\n"+src, 0); err == nil && len(file.Decls) == 1 {
+		return file.Decls[0], nil
+	}
+	file, err := parser.ParseFile(fset, "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	if err != nil {
+		return nil, errors.New("expected a Go expression, statement or declaration")
+	}
+	body := file.Decls[0].(*ast.FuncDecl).Body.List
+	if len(body) != 1 {
+		return nil, errors.New("expected a single expression, statement or declaration")
+	}
+	return body[0], nil
+}
+
+// isWildcard checks whether the identifier is an anonymous metavariable
+func isWildcard(id *ast.Ident) bool {
+	return id.Name == anyName || id.Name == ellipsisName
+}
+
+var (
+	posType          = reflect.TypeOf(token.NoPos)
+	objectType       = reflect.TypeOf((*ast.Object)(nil))
+	scopeType        = reflect.TypeOf((*ast.Scope)(nil))
+	commentGroupType = reflect.TypeOf((*ast.CommentGroup)(nil))
+)
+
+// skipField checks whether a field of a node is irrelevant to the matches, such as
+// the positions and the comments
+func skipField(field reflect.StructField) bool {
+	switch field.Type {
+	case posType, objectType, scopeType, commentGroupType:
+		return true
+	}
+	switch field.Name {
+	case "Comments", "Imports", "Unresolved", "Incomplete":
+		return true
+	}
+	return false
+}
+
+// matcher matches a pattern against a node
+type matcher struct {
+	pattern  *Pattern
+	info     *types.Info
+	bindings Bindings
+}
+
+// match matches the values of the same field of a pattern node and of a code node
+func (m *matcher) match(p, n reflect.Value) bool {
+	if p.Kind() == reflect.Interface {
+		if p.IsNil() {
+			return n.IsNil()
+		}
+		p = p.Elem()
+	}
+	if n.Kind() == reflect.Interface {
+		if n.IsNil() {
+			return false
+		}
+		n = n.Elem()
+	}
+
+	switch p.Kind() {
+	case reflect.Ptr:
+		if p.IsNil() || n.Kind() != reflect.Ptr || n.IsNil() {
+			return p.IsNil() && n.Kind() == reflect.Ptr && n.IsNil()
+		}
+		if pn, ok := p.Interface().(ast.Node); ok {
+			nn, ok := n.Interface().(ast.Node)
+			return ok && m.matchNode(pn, nn)
+		}
+		return p.Type() == n.Type() && m.match(p.Elem(), n.Elem())
+	case reflect.Struct:
+		if p.Type() != n.Type() {
+			return false
+		}
+		for i := 0; i < p.NumField(); i++ {
+			if skipField(p.Type().Field(i)) {
+				continue
+			}
+			if !m.match(p.Field(i), n.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		return p.Type() == n.Type() && m.matchList(p, 0, n, 0)
+	default:
+		return p.Kind() == n.Kind() && p.Interface() == n.Interface()
+	}
+}
+
+// matchNode matches a pattern node against a code node
+func (m *matcher) matchNode(p, n ast.Node) bool {
+	if _, ok := p.(*ast.ParenExpr); !ok {
+		for {
+			paren, ok := n.(*ast.ParenExpr)
+			if !ok {
+				break
+			}
+			n = paren.X
+		}
+	}
+	if id, ok := p.(*ast.Ident); ok {
+		switch {
+		case isWildcard(id):
+			return true
+		case strings.HasPrefix(id.Name, metaPrefix):
+			return m.bind("$"+strings.TrimPrefix(id.Name, metaPrefix), []ast.Node{n})
+		}
+		nid, ok := n.(*ast.Ident)
+		return ok && (id.Name == nid.Name || m.isPackage(nid, id.Name))
+	}
+	pv, nv := reflect.ValueOf(p), reflect.ValueOf(n)
+	return pv.Type() == nv.Type() && m.match(pv.Elem(), nv.Elem())
+}
+
+// matchList matches the pattern elements from i against the code elements from j,
+// trying all the lengths of the sequences matched by the ellipses
+func (m *matcher) matchList(p reflect.Value, i int, n reflect.Value, j int) bool {
+	if i == p.Len() {
+		return j == n.Len()
+	}
+	if name, ok := listElement(p.Index(i)); ok {
+		for k := j; k <= n.Len(); k++ {
+			saved := m.save()
+			if m.bindList(name, n, j, k) && m.matchList(p, i+1, n, k) {
+				return true
+			}
+			m.bindings = saved
+		}
+		return false
+	}
+	if j == n.Len() {
+		return false
+	}
+	saved := m.save()
+	if m.match(p.Index(i), n.Index(j)) && m.matchList(p, i+1, n, j+1) {
+		return true
+	}
+	m.bindings = saved
+	return false
+}
+
+// listElement checks whether a pattern element matches a sequence, and returns
+// the name of its metavariable, which is empty for an ellipsis
+func listElement(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return "", false
+	}
+	node, ok := v.Interface().(ast.Node)
+	if !ok {
+		return "", false
+	}
+	switch n := node.(type) {
+	case *ast.ExprStmt:
+		node = n.X
+	case *ast.Field:
+		if len(n.Names) == 0 {
+			node = n.Type
+		}
+	}
+	id, ok := node.(*ast.Ident)
+	switch {
+	case !ok:
+		return "", false
+	case id.Name == ellipsisName:
+		return "", true
+	case strings.HasPrefix(id.Name, metaListPrefix):
+		return "$" + strings.TrimPrefix(id.Name, metaListPrefix), true
+	}
+	return "", false
+}
+
+// bindList binds the metavariable to the code elements from j to k
+func (m *matcher) bindList(name string, n reflect.Value, j, k int) bool {
+	if name == "" {
+		return true
+	}
+	nodes := make([]ast.Node, 0, k-j)
+	for ; j < k; j++ {
+		node, ok := n.Index(j).Interface().(ast.Node)
+		if !ok {
+			return false
+		}
+		nodes = append(nodes, node)
+	}
+	return m.bind(name, nodes)
+}
+
+// bind binds the metavariable to the nodes, which must satisfy its constraints and
+// be identical to the nodes already bound to it
+func (m *matcher) bind(name string, nodes []ast.Node) bool {
+	for _, c := range m.pattern.constraints[name] {
+		for _, n := range nodes {
+			if !c(n, m.info) {
+				return false
+			}
+		}
+	}
+	bound, ok := m.bindings[name]
+	if !ok {
+		m.bindings[name] = nodes
+		return true
+	}
+	if len(bound) != len(nodes) {
+		return false
+	}
+	for i := range nodes {
+		identical := &matcher{pattern: m.pattern, info: m.info, bindings: Bindings{}}
+		if !identical.match(reflect.ValueOf(bound[i]), reflect.ValueOf(nodes[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// save returns a copy of the bindings, restored when a match is backtracked
+func (m *matcher) save() Bindings {
+	saved := make(Bindings, len(m.bindings))
+	for name, nodes := range m.bindings {
+		saved[name] = nodes
+	}
+	return saved
+}
+
+// isPackage checks whether the identifier refers to an imported package with the
+// given name, which can be imported under another name
+func (m *matcher) isPackage(id *ast.Ident, name string) bool {
+	if m.info == nil {
+		return false
+	}
+	pkg, ok := m.info.Uses[id].(*types.PkgName)
+	return ok && pkg.Imported().Name() == name
+}
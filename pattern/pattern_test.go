@@ -0,0 +1,176 @@
+package pattern_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2/pattern"
+)
+
+// checkSource parses and type checks the source of a package
+func checkSource(src string) (*ast.File, *types.Info) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, 0)
+	Expect(err).ShouldNot(HaveOccurred())
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("main", fset, []*ast.File{file}, info)
+	Expect(err).ShouldNot(HaveOccurred())
+	return file, info
+}
+
+// matches returns the source of the nodes of the file matching the pattern
+func matches(p *pattern.Pattern, file *ast.File, info *types.Info) []string {
+	var found []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if _, ok := p.Match(n, info, nil); ok {
+			found = append(found, types.ExprString(n.(ast.Expr)))
+		}
+		return true
+	})
+	return found
+}
+
+var _ = Describe("Pattern", func() {
+	It("should match the calls with metavariables and sequences", func() {
+		file, info := checkSource(`
+			package main
+			import (
+				"database/sql"
+				"fmt"
+				str "strings"
+			)
+			func main() {
+				db, _ := sql.Open("sqlite3", ":memory:")
+				db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = %d", "t", 1))
+				db.Exec(fmt.Sprintf("DELETE FROM t"))
+				db.Exec(("DELETE FROM t"))
+				db.Exec(fmt.Sprint("DELETE FROM t"))
+				println(str.Repeat("a", 2))
+			}`)
+		p, err := pattern.Compile("$DB.Exec(fmt.Sprintf($FMT, $...ARGS))")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.Node()).To(Equal((*ast.CallExpr)(nil)))
+		Expect(p.Metavariables()).To(Equal([]string{"$ARGS", "$DB", "$FMT"}))
+		Expect(matches(p, file, info)).To(Equal([]string{
+			`db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = %d", "t", 1))`,
+			`db.Exec(fmt.Sprintf("DELETE FROM t"))`,
+		}))
+
+		var bindings pattern.Bindings
+		ast.Inspect(file, func(n ast.Node) bool {
+			if b, ok := p.Match(n, info, nil); ok && bindings == nil {
+				bindings = b
+			}
+			return true
+		})
+		Expect(bindings["$DB"]).To(HaveLen(1))
+		Expect(bindings["$ARGS"]).To(HaveLen(2))
+
+		p, err = pattern.Compile(`$DB.Exec("DELETE FROM t")`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(matches(p, file, info)).To(Equal([]string{`db.Exec(("DELETE FROM t"))`}))
+
+		p, err = pattern.Compile(`strings.Repeat(...)`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(matches(p, file, info)).To(Equal([]string{`str.Repeat("a", 2)`}))
+	})
+
+	It("should match the composite literals with ellipses", func() {
+		file, info := checkSource(`
+			package main
+			import "net/http"
+			func main() {
+				_ = http.Cookie{Name: "a", Secure: false, HttpOnly: true}
+				_ = http.Cookie{Name: "b", Secure: true}
+				_ = http.Cookie{Secure: false}
+			}`)
+		p, err := pattern.Compile("http.Cookie{..., Secure: false, ...}")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.Node()).To(Equal((*ast.CompositeLit)(nil)))
+		Expect(matches(p, file, info)).To(HaveLen(2))
+	})
+
+	It("should require identical nodes for a repeated metavariable", func() {
+		file, info := checkSource(`
+			package main
+			func main() {
+				a, b := 1, 2
+				println(a+a, a+b, (a)+a)
+			}`)
+		p, err := pattern.Compile("$X + $X")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(matches(p, file, info)).To(Equal([]string{"a + a", "(a) + a"}))
+	})
+
+	It("should check the constraints on the metavariables", func() {
+		file, info := checkSource(`
+			package main
+			import "os"
+			type store struct{}
+			func (store) Close() error { return nil }
+			func main() {
+				f, _ := os.Open("/tmp/a")
+				f.Close()
+				store{}.Close()
+			}`)
+		p, err := pattern.Compile("$F.Close()")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.HasMetavariable("$F")).To(BeTrue())
+		p.Where("$F", pattern.TypeIs("*os.File"))
+		Expect(matches(p, file, info)).To(Equal([]string{"f.Close()"}))
+	})
+
+	It("should match the statements and the declarations", func() {
+		file, info := checkSource(`
+			package main
+			import "sync"
+			var mu sync.Mutex
+			func locked() {
+				mu.Lock()
+				println("locked")
+			}
+			func unlocked() {
+				println("unlocked")
+			}`)
+		p, err := pattern.Compile("func $F(...) {\n$M.Lock()\n...\n}")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.Node()).To(Equal((*ast.FuncDecl)(nil)))
+		var names []string
+		for _, decl := range file.Decls {
+			if bindings, ok := p.Match(decl, info, nil); ok {
+				names = append(names, bindings["$F"][0].(*ast.Ident).Name)
+			}
+		}
+		Expect(names).To(Equal([]string{"locked"}))
+
+		p, err = pattern.Compile("$M.Lock()")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.Node()).To(Equal((*ast.CallExpr)(nil)))
+
+		p, err = pattern.Compile("$X = nil")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.Node()).To(Equal((*ast.AssignStmt)(nil)))
+	})
+
+	It("should reject the invalid patterns", func() {
+		_, err := pattern.Compile("$X")
+		Expect(err).To(MatchError(ContainSubstring("it matches any node")))
+		_, err = pattern.Compile("$.Close()")
+		Expect(err).To(MatchError(ContainSubstring("metavariable without name")))
+		_, err = pattern.Compile("$X.Close(")
+		Expect(err).To(MatchError(ContainSubstring("expected a Go expression, statement or declaration")))
+		_, err = pattern.Compile("$X = 1\n$Y = 2")
+		Expect(err).To(MatchError(ContainSubstring("expected a single expression, statement or declaration")))
+	})
+})
@@ -0,0 +1,182 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Platform is a combination of GOOS, GOARCH and build tags for which the packages
+// are loaded and checked
+type Platform struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// ParsePlatform parses a platform in the goos/goarch[:tag1+tag2] format,
+// e.g. "linux/arm64" or "windows/amd64:netgo+debug"
+func ParsePlatform(s string) (Platform, error) {
+	target, tags := s, ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		target, tags = s[:i], s[i+1:]
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected goos/goarch[:tag1+tag2]", s)
+	}
+	platform := Platform{GOOS: parts[0], GOARCH: parts[1]}
+	for _, tag := range strings.Split(tags, "+") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			platform.Tags = append(platform.Tags, tag)
+		}
+	}
+	return platform, nil
+}
+
+// ParsePlatforms parses a comma separated list of platforms
+func ParsePlatforms(s string) ([]Platform, error) {
+	var platforms []Platform
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		platform, err := ParsePlatform(item)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// String formats the platform in the format accepted by ParsePlatform
+func (p Platform) String() string {
+	s := p.GOOS + "/" + p.GOARCH
+	if len(p.Tags) > 0 {
+		s += ":" + strings.Join(p.Tags, "+")
+	}
+	return s
+}
+
+// env returns the environment of the go command loading the packages
+func (p Platform) env() []string {
+	return append(os.Environ(), "GOOS="+p.GOOS, "GOARCH="+p.GOARCH)
+}
+
+// loadEnv returns the environment used to load the packages, which is nil when
+// the packages are loaded for the current platform
+func (gosec *Analyzer) loadEnv() []string {
+	if gosec.platform == nil {
+		return nil
+	}
+	return gosec.platform.env()
+}
+
+// processPlatforms loads and checks the packages for each platform, and merges
+// the issues found on several platforms. The metrics are summed, hence the
+// files checked for several platforms are counted several times.
+func (gosec *Analyzer) processPlatforms(buildTags []string, packagePaths ...string) error {
+	issues := make(map[string]*Issue)
+	suppressed := make(map[string]*Issue)
+	checked := make(map[string]int)
+	defer func() {
+		gosec.removeUsedNosec(checked)
+		sortErrors(gosec.errors)
+	}()
+	for i := range gosec.platforms {
+		platform := gosec.platforms[i]
+		gosec.logger.Println("Checking platform:", platform)
+		variant := gosec.fork()
+		variant.concurrency = gosec.concurrency
+		variant.platform = &platform
+		// the resolution of the values depends on the files of the platform
+		variant.resolved = newResolveCache()
+		tags := append(append([]string{}, buildTags...), platform.Tags...)
+		err := variant.process(tags, packagePaths...)
+		gosec.mergePlatform(variant, platform, issues, suppressed)
+		for _, file := range variant.files {
+			checked[file]++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUsedNosec removes the stale #nosec issues which were not found on every
+// platform for which their file was checked, since the directive suppressed an
+// issue on the other platforms
+func (gosec *Analyzer) removeUsedNosec(checked map[string]int) {
+	issues := gosec.issues[:0]
+	for _, issue := range gosec.issues {
+		if issue.RuleID == StaleNosecRuleID && len(issue.Platforms) < checked[issue.File] {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	gosec.issues = issues
+	gosec.stats.NumFound = len(gosec.issues)
+}
+
+// mergePlatform merges the results of the analysis of a platform. The issues
+// already found on another platform are not added again, but the platform is
+// recorded in the issue.
+func (gosec *Analyzer) mergePlatform(variant *Analyzer, platform Platform, issues, suppressed map[string]*Issue) {
+	name := platform.String()
+	gosec.issues = mergePlatformIssues(gosec.issues, variant.issues, name, issues)
+	gosec.suppressed = mergePlatformIssues(gosec.suppressed, variant.suppressed, name, suppressed)
+	gosec.stats.merge(variant.stats)
+	gosec.stats.NumFound = len(gosec.issues)
+
+	failures := make(map[RuleFailure]bool, len(gosec.failures))
+	for _, failure := range gosec.failures {
+		failures[*failure] = true
+	}
+	for _, failure := range variant.failures {
+		if !failures[*failure] {
+			failures[*failure] = true
+			gosec.failures = append(gosec.failures, failure)
+		}
+	}
+
+	for file, errs := range variant.errors {
+		known := make(map[Error]bool, len(gosec.errors[file]))
+		for _, err := range gosec.errors[file] {
+			known[err] = true
+		}
+		for _, err := range errs {
+			if !known[err] {
+				known[err] = true
+				gosec.errors[file] = append(gosec.errors[file], err)
+			}
+		}
+	}
+}
+
+func mergePlatformIssues(current, found []*Issue, platform string, seen map[string]*Issue) []*Issue {
+	for _, issue := range found {
+		key := strings.Join([]string{issue.RuleID, issue.File, issue.Line, issue.Col, issue.What}, "\x00")
+		if known, ok := seen[key]; ok {
+			known.Platforms = append(known.Platforms, platform)
+			continue
+		}
+		issue.Platforms = []string{platform}
+		seen[key] = issue
+		current = append(current, issue)
+	}
+	return current
+}
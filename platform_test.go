@@ -0,0 +1,42 @@
+package gosec_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+)
+
+var _ = Describe("Platform", func() {
+	It("should parse the goos and the goarch", func() {
+		platform, err := gosec.ParsePlatform("linux/arm64")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(platform).To(Equal(gosec.Platform{GOOS: "linux", GOARCH: "arm64"}))
+		Expect(platform.String()).To(Equal("linux/arm64"))
+	})
+
+	It("should parse the build tags", func() {
+		platform, err := gosec.ParsePlatform("windows/amd64:netgo+debug")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(platform.Tags).To(Equal([]string{"netgo", "debug"}))
+		Expect(platform.String()).To(Equal("windows/amd64:netgo+debug"))
+	})
+
+	It("should report an invalid platform", func() {
+		for _, s := range []string{"linux", "linux/", "/amd64", "linux/amd64/v2"} {
+			_, err := gosec.ParsePlatform(s)
+			Expect(err).Should(HaveOccurred(), s)
+		}
+	})
+
+	It("should parse a list of platforms", func() {
+		platforms, err := gosec.ParsePlatforms("linux/amd64, darwin/arm64,")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(platforms).To(HaveLen(2))
+		Expect(platforms[1].GOOS).To(Equal("darwin"))
+
+		platforms, err = gosec.ParsePlatforms("")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(platforms).To(BeEmpty())
+	})
+})
@@ -0,0 +1,323 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin runs the rules implemented by external executables, the plugins,
+// which can be written in any language. Gosec starts each plugin declared in the
+// plugins section of its configuration, and talks to it over its stdin and stdout
+// with JSON messages, one per line:
+//
+//   - the initialize request asks for the manifest of the plugin, listing its
+//     rules and the kinds of the nodes it checks
+//   - a check request is sent for each file, with the positions of its nodes of
+//     these kinds and the facts known about them (types, constants and callees),
+//     and the plugin answers with the issues found on the nodes
+//   - the shutdown request is sent at the end, the plugin must also exit when its
+//     stdin is closed
+//
+// The rules of the plugins are registered along with the rules of gosec, so that
+// their issues are filtered and suppressed by #nosec like the other issues.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+)
+
+// DefaultTimeout is the time given to a plugin to answer a request, unless the
+// configuration of the plugin sets another one
+const DefaultTimeout = time.Minute
+
+// Config declares a plugin in the plugins section of the gosec configuration
+type Config struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Timeout is the time given to the plugin to answer a request, e.g. "30s".
+	// The plugin is killed when it doesn't answer in time.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Plugin is a running plugin
+type Plugin struct {
+	name     string
+	config   Config
+	ctx      context.Context
+	timeout  time.Duration
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	encoder  *json.Encoder
+	decoder  *json.Decoder
+	manifest *Manifest
+
+	mu  sync.Mutex // serializes the requests
+	err error      // error which broke the communication with the plugin
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Start starts a plugin and reads its manifest. The plugin is killed when the
+// context is done.
+func Start(ctx context.Context, conf Config) (*Plugin, error) {
+	if conf.Command == "" {
+		return nil, fmt.Errorf("plugin %q without command", conf.Name)
+	}
+	name := conf.Name
+	if name == "" {
+		name = conf.Command
+	}
+	timeout := DefaultTimeout
+	if conf.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(conf.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("plugin %s: invalid timeout %q", name, conf.Timeout)
+		}
+	}
+	cmd := exec.CommandContext(ctx, conf.Command, conf.Args...) // #nosec G204
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting plugin %s: %v", name, err)
+	}
+	p := &Plugin{
+		name:    name,
+		config:  conf,
+		ctx:     ctx,
+		timeout: timeout,
+		cmd:     cmd,
+		stdin:   stdin,
+		encoder: json.NewEncoder(stdin),
+		decoder: json.NewDecoder(bufio.NewReader(stdout)),
+	}
+
+	resp, err := p.call(&Request{Method: MethodInitialize})
+	if err == nil && resp.Manifest == nil {
+		err = errors.New("no manifest")
+	}
+	if err == nil {
+		err = validateManifest(resp.Manifest)
+	}
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+	p.manifest = resp.Manifest
+	return p, nil
+}
+
+// validateManifest checks the rules and the kinds of nodes of a manifest
+func validateManifest(manifest *Manifest) error {
+	if len(manifest.Rules) == 0 {
+		return errors.New("no rules in the manifest")
+	}
+	for _, rule := range manifest.Rules {
+		if rule.ID == "" || rule.CWE == "" {
+			return fmt.Errorf("rule %q without ID or CWE", rule.ID)
+		}
+	}
+	if len(manifest.Nodes) == 0 {
+		return errors.New("no kinds of nodes in the manifest")
+	}
+	for _, kind := range manifest.Nodes {
+		if _, ok := nodeKinds[kind]; !ok {
+			return fmt.Errorf("unknown kind of node %q", kind)
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the plugin
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Manifest returns the manifest of the plugin
+func (p *Plugin) Manifest() *Manifest {
+	return p.manifest
+}
+
+// Identity returns the data identifying the plugin and its rules, i.e. its
+// command line, a hash of its executable and its manifest
+func (p *Plugin) Identity() ([]byte, error) {
+	file, err := os.Open(p.cmd.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // #nosec G307
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Config     Config    `json:"config"`
+		Executable string    `json:"executable"`
+		Manifest   *Manifest `json:"manifest"`
+	}{p.config, hex.EncodeToString(hash.Sum(nil)), p.manifest})
+}
+
+// call sends a request and reads its response. The plugin is killed when it
+// doesn't answer before the timeout, and the requests fail once the communication
+// with the plugin is broken.
+func (p *Plugin) call(req *Request) (*Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	req.Protocol = ProtocolVersion
+	var resp Response
+	timer := time.AfterFunc(p.timeout, func() {
+		_ = p.cmd.Process.Kill()
+	})
+	err := p.encoder.Encode(req)
+	if err != nil {
+		err = fmt.Errorf("writing to plugin %s: %v", p.name, err)
+	} else if err = p.decoder.Decode(&resp); err != nil {
+		err = fmt.Errorf("reading from plugin %s: %v", p.name, err)
+	}
+	switch {
+	case !timer.Stop():
+		p.err = fmt.Errorf("plugin %s didn't answer within %s", p.name, p.timeout)
+		return nil, p.err
+	case p.ctx.Err() != nil:
+		p.err = fmt.Errorf("plugin %s: %v", p.name, p.ctx.Err())
+		return nil, p.err
+	case err != nil:
+		p.err = err
+		return nil, p.err
+	}
+	if resp.Protocol != ProtocolVersion {
+		p.err = fmt.Errorf("plugin %s speaks the protocol %d instead of %d", p.name, resp.Protocol, ProtocolVersion)
+		return nil, p.err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+	return &resp, nil
+}
+
+// Close asks the plugin to exit, and waits for its exit
+func (p *Plugin) Close() error {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		if p.err == nil {
+			_ = p.encoder.Encode(&Request{Protocol: ProtocolVersion, Method: MethodShutdown})
+			p.err = fmt.Errorf("plugin %s is closed", p.name)
+		}
+		p.mu.Unlock()
+		_ = p.stdin.Close()
+		p.closeErr = p.cmd.Wait()
+	})
+	return p.closeErr
+}
+
+// Rules returns the definitions of the rules of the plugin, tagged "plugin"
+func (p *Plugin) Rules() []rules.RuleDefinition {
+	definitions := make([]rules.RuleDefinition, 0, len(p.manifest.Rules))
+	for _, info := range p.manifest.Rules {
+		info := info
+		definitions = append(definitions, rules.RuleDefinition{
+			ID:          info.ID,
+			Description: info.Description,
+			Severity:    info.Severity,
+			Confidence:  info.Confidence,
+			CWE:         info.CWE,
+			Tags:        []string{"plugin"},
+			Create:      p.newRule(info),
+		})
+	}
+	return definitions
+}
+
+// Load starts the plugins declared in the plugins section of the configuration,
+// and registers their rules. The plugins must be closed at the end of the scans,
+// and they are killed when the context is done.
+func Load(ctx context.Context, conf gosec.Config) ([]*Plugin, error) {
+	section, err := conf.Get(gosec.PluginsSection)
+	if err != nil {
+		return nil, nil
+	}
+	var configs []Config
+	data, err := json.Marshal(section)
+	if err == nil {
+		err = json.Unmarshal(data, &configs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %v", gosec.PluginsSection, err)
+	}
+
+	var plugins []*Plugin
+	known := rules.Generate()
+	for _, c := range configs {
+		p, err := Start(ctx, c)
+		if err == nil {
+			err = checkRuleIDs(p, known)
+		}
+		if err != nil {
+			if p != nil {
+				p.Close()
+			}
+			Close(plugins)
+			return nil, err
+		}
+		plugins = append(plugins, p)
+	}
+	for _, p := range plugins {
+		for _, def := range p.Rules() {
+			rules.Register(def)
+		}
+	}
+	return plugins, nil
+}
+
+// checkRuleIDs checks that the rules of the plugin are not already defined
+func checkRuleIDs(p *Plugin, known rules.RuleList) error {
+	for _, def := range p.Rules() {
+		if _, ok := known[def.ID]; ok {
+			return fmt.Errorf("plugin %s: rule %s is already defined", p.name, def.ID)
+		}
+		known[def.ID] = def
+	}
+	return nil
+}
+
+// Close closes the plugins, and returns the first error
+func Close(plugins []*Plugin) error {
+	var first error
+	for _, p := range plugins {
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
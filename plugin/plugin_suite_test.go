@@ -0,0 +1,67 @@
+package plugin_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/plugin"
+)
+
+// pluginEnv makes the test binary run as a plugin
+const pluginEnv = "GOSEC_TEST_PLUGIN"
+
+// testManifest is the manifest of the test plugin
+var testManifest = &plugin.Manifest{
+	Rules: []plugin.RuleInfo{
+		{ID: "P101", Description: "Audit the calls to os.Exit", Severity: gosec.Low, Confidence: gosec.High, CWE: "705"},
+		{ID: "P102", Description: "Secret printed", Severity: gosec.Medium, Confidence: gosec.Medium, CWE: "532"},
+	},
+	Nodes: []string{"CallExpr"},
+}
+
+// testCheck flags the calls to os.Exit, and the secrets printed by fmt.Println
+func testCheck(req *plugin.CheckRequest) ([]plugin.Issue, error) {
+	if req.Package.Name == "broken" {
+		return nil, fmt.Errorf("cannot check the package %s", req.Package.Path)
+	}
+	if req.Package.Name == "slow" {
+		// never answers, the plugin is blocked until it's killed
+		for {
+			time.Sleep(time.Hour)
+		}
+	}
+	var issues []plugin.Issue
+	for _, node := range req.Nodes {
+		switch {
+		case node.Callee == "os.Exit":
+			issues = append(issues, plugin.Issue{Node: node.ID, RuleID: "P101"})
+		case node.Callee == "fmt.Println" && len(node.Args) > 0 && strings.Contains(node.Args[0].Value, "password"):
+			high := gosec.High
+			issues = append(issues, plugin.Issue{Node: node.ID, RuleID: "P102", What: "Password printed", Severity: &high, CWE: "200"})
+		}
+	}
+	return issues, nil
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) == "1" {
+		if err := plugin.Serve(os.Stdin, os.Stdout, testManifest, testCheck); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestPlugin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plugin Suite")
+}
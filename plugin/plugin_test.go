@@ -0,0 +1,155 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/plugin"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Plugin", func() {
+	var testPlugin *plugin.Plugin
+
+	BeforeEach(func() {
+		os.Setenv(pluginEnv, "1")
+		var err error
+		testPlugin, err = plugin.Start(context.Background(), plugin.Config{Name: "test", Command: os.Args[0]})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(testPlugin.Close()).Should(Succeed())
+	})
+
+	// analyze checks a package with the rules of the test plugin
+	analyze := func(src string) *gosec.Analyzer {
+		pkg := testutils.NewTestPackage()
+		defer pkg.Close()
+		pkg.AddFile("main.go", src)
+		Expect(pkg.Build()).Should(Succeed())
+
+		ruleList := rules.RuleList{}
+		for _, def := range testPlugin.Rules() {
+			ruleList[def.ID] = def
+		}
+		logger, _ := testutils.NewLogger()
+		analyzer := gosec.NewAnalyzer(nil, false, logger)
+		analyzer.LoadRules(ruleList.Builders())
+		Expect(analyzer.Process(nil, pkg.Path)).Should(Succeed())
+		return analyzer
+	}
+
+	It("should report the issues of the plugin and respect #nosec", func() {
+		Expect(testPlugin.Manifest().Rules).To(HaveLen(2))
+		Expect(testPlugin.Rules()[0].Tags).To(Equal([]string{"plugin"}))
+		identity, err := testPlugin.Identity()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(identity)).To(ContainSubstring(`"P101"`))
+
+		analyzer := analyze(`
+			package main
+			import (
+				"fmt"
+				"os"
+			)
+			func main() {
+				fmt.Println("password: hunter2")
+				fmt.Println("hello")
+				os.Exit(1) // #nosec P101 -- exits on purpose
+				os.Exit(2)
+			}`)
+		issues, _, _ := analyzer.Report()
+		Expect(analyzer.RuleFailures()).To(BeEmpty())
+		Expect(issues).To(HaveLen(2))
+		Expect(issues[0].RuleID).To(Equal("P102"))
+		Expect(issues[0].Line).To(Equal("8"))
+		Expect(issues[0].What).To(Equal("Password printed"))
+		Expect(issues[0].Severity).To(Equal(gosec.High))
+		Expect(issues[0].Confidence).To(Equal(gosec.Medium))
+		Expect(issues[0].Cwe.ID).To(Equal("200"))
+		Expect(issues[1].RuleID).To(Equal("P101"))
+		Expect(issues[1].Line).To(Equal("11"))
+		Expect(issues[1].What).To(Equal("Audit the calls to os.Exit"))
+		Expect(issues[1].Severity).To(Equal(gosec.Low))
+	})
+
+	It("should report an error of the plugin once per file", func() {
+		analyzer := analyze(`
+			package broken
+			import "os"
+			func Exit() {
+				os.Exit(1)
+				os.Exit(2)
+			}`)
+		issues, _, _ := analyzer.Report()
+		Expect(issues).To(BeEmpty())
+		Expect(analyzer.RuleFailures()).To(HaveLen(1))
+		Expect(analyzer.RuleFailures()[0].Err).To(ContainSubstring("cannot check the package"))
+	})
+
+	It("should kill the plugin when it doesn't answer in time", func() {
+		slow, err := plugin.Start(context.Background(), plugin.Config{Name: "slow", Command: os.Args[0], Timeout: "10s"})
+		Expect(err).ShouldNot(HaveOccurred())
+		defer slow.Close()
+		ruleList := rules.RuleList{}
+		for _, def := range slow.Rules() {
+			ruleList[def.ID] = def
+		}
+
+		pkg := testutils.NewTestPackage()
+		defer pkg.Close()
+		pkg.AddFile("main.go", `
+			package slow
+			import "os"
+			func Exit() {
+				os.Exit(1)
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+		logger, _ := testutils.NewLogger()
+		analyzer := gosec.NewAnalyzer(nil, false, logger)
+		analyzer.LoadRules(ruleList.Builders())
+		Expect(analyzer.Process(nil, pkg.Path)).Should(Succeed())
+		Expect(analyzer.RuleFailures()).To(HaveLen(1))
+		Expect(analyzer.RuleFailures()[0].Err).To(Equal("plugin slow didn't answer within 10s"))
+	})
+
+	It("should reject an invalid timeout", func() {
+		_, err := plugin.Start(context.Background(), plugin.Config{Name: "test", Command: os.Args[0], Timeout: "soon"})
+		Expect(err).To(MatchError(`plugin test: invalid timeout "soon"`))
+	})
+
+	It("should register the rules of the plugins declared in the configuration", func() {
+		plugins, err := plugin.Load(context.Background(), gosec.NewConfig())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(plugins).To(BeEmpty())
+
+		conf := gosec.NewConfig()
+		conf.Set(gosec.PluginsSection, []interface{}{
+			map[string]interface{}{"name": "test", "command": os.Args[0]},
+		})
+		plugins, err = plugin.Load(context.Background(), conf)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer plugin.Close(plugins)
+		Expect(plugins).To(HaveLen(1))
+		ruleList := rules.Generate()
+		Expect(ruleList).To(HaveKey("P101"))
+		Expect(ruleList["P102"].CWE).To(Equal("532"))
+
+		_, err = plugin.Load(context.Background(), conf)
+		Expect(err).To(MatchError("plugin test: rule P101 is already defined"))
+	})
+
+	It("should reject the requests of another protocol version", func() {
+		var out bytes.Buffer
+		in := bytes.NewBufferString(`{"protocol": 2, "method": "initialize"}` + "\n")
+		Expect(plugin.Serve(in, &out, testManifest, nil)).Should(Succeed())
+		Expect(out.String()).To(ContainSubstring("unsupported protocol 2, expected 1"))
+	})
+})
@@ -0,0 +1,112 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import "github.com/securego/gosec/v2"
+
+// ProtocolVersion is the version of the protocol spoken with the plugins. It
+// changes when the messages change in a way which breaks the existing plugins.
+const ProtocolVersion = 1
+
+// The methods of the requests sent to a plugin
+const (
+	// MethodInitialize asks for the manifest of the plugin, it's the first request
+	MethodInitialize = "initialize"
+	// MethodCheck asks for the issues of a file
+	MethodCheck = "check"
+	// MethodShutdown asks the plugin to exit, it's the last request
+	MethodShutdown = "shutdown"
+)
+
+// Request is a request sent by gosec to a plugin, on a single line of its stdin
+type Request struct {
+	Protocol int           `json:"protocol"`
+	Method   string        `json:"method"`
+	Check    *CheckRequest `json:"check,omitempty"`
+}
+
+// Response is the response of a plugin to a request, on a single line of its stdout
+type Response struct {
+	Protocol int       `json:"protocol"`
+	Manifest *Manifest `json:"manifest,omitempty"` // response to the initialize request
+	Issues   []Issue   `json:"issues,omitempty"`   // response to the check requests
+	Error    string    `json:"error,omitempty"`
+}
+
+// Manifest describes the rules of a plugin, and the kinds of the nodes it checks
+type Manifest struct {
+	Rules []RuleInfo `json:"rules"`
+	// Nodes are the kinds of the nodes sent to the plugin, named after the types of
+	// the go/ast package, e.g. "CallExpr"
+	Nodes []string `json:"nodes"`
+}
+
+// RuleInfo describes a rule of a plugin, along with the default severity and
+// confidence of its issues
+type RuleInfo struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description"`
+	Severity    gosec.Score `json:"severity"`
+	Confidence  gosec.Score `json:"confidence"`
+	CWE         string      `json:"cwe"`
+}
+
+// CheckRequest holds the nodes of a file, of the kinds listed by the manifest
+type CheckRequest struct {
+	Package Package `json:"package"`
+	File    string  `json:"file"`
+	Nodes   []Node  `json:"nodes"`
+}
+
+// Package identifies the package of the checked file
+type Package struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// Node holds the position of a node and the facts known about it
+type Node struct {
+	ID    int            `json:"id"`
+	Kind  string         `json:"kind"`
+	Start gosec.Position `json:"start"`
+	End   gosec.Position `json:"end"`
+	// Text is the source of an expression
+	Text string `json:"text,omitempty"`
+	// Type is the type of an expression, e.g. "*database/sql.DB"
+	Type string `json:"type,omitempty"`
+	// Value is the value of a constant expression, e.g. "\"SELECT 1\"" or "42"
+	Value string `json:"value,omitempty"`
+	// Callee is the full name of the function called by a call, e.g. "os.Exit" or
+	// "(*database/sql.DB).Query"
+	Callee string `json:"callee,omitempty"`
+	// Args are the arguments of a call
+	Args []Expr `json:"args,omitempty"`
+}
+
+// Expr holds the facts known about an expression
+type Expr struct {
+	Text  string `json:"text"`
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Issue is an issue found by a plugin on a node. The severity, the confidence and
+// the CWE of the rule are used when they are not given.
+type Issue struct {
+	Node       int          `json:"node"`
+	RuleID     string       `json:"rule_id"`
+	What       string       `json:"what"`
+	Severity   *gosec.Score `json:"severity,omitempty"`
+	Confidence *gosec.Score `json:"confidence,omitempty"`
+	CWE        string       `json:"cwe,omitempty"`
+}
@@ -0,0 +1,229 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/cwe"
+)
+
+// nodeKinds are the kinds of nodes which can be sent to the plugins
+var nodeKinds = func() map[string]ast.Node {
+	nodes := []ast.Node{
+		(*ast.ArrayType)(nil), (*ast.AssignStmt)(nil), (*ast.BasicLit)(nil), (*ast.BinaryExpr)(nil),
+		(*ast.BlockStmt)(nil), (*ast.BranchStmt)(nil), (*ast.CallExpr)(nil), (*ast.CaseClause)(nil),
+		(*ast.ChanType)(nil), (*ast.CommClause)(nil), (*ast.CompositeLit)(nil), (*ast.DeclStmt)(nil),
+		(*ast.DeferStmt)(nil), (*ast.Ellipsis)(nil), (*ast.ExprStmt)(nil), (*ast.Field)(nil),
+		(*ast.File)(nil), (*ast.ForStmt)(nil), (*ast.FuncDecl)(nil), (*ast.FuncLit)(nil),
+		(*ast.FuncType)(nil), (*ast.GenDecl)(nil), (*ast.GoStmt)(nil), (*ast.Ident)(nil),
+		(*ast.IfStmt)(nil), (*ast.ImportSpec)(nil), (*ast.IncDecStmt)(nil), (*ast.IndexExpr)(nil),
+		(*ast.InterfaceType)(nil), (*ast.KeyValueExpr)(nil), (*ast.LabeledStmt)(nil), (*ast.MapType)(nil),
+		(*ast.ParenExpr)(nil), (*ast.RangeStmt)(nil), (*ast.ReturnStmt)(nil), (*ast.SelectStmt)(nil),
+		(*ast.SelectorExpr)(nil), (*ast.SendStmt)(nil), (*ast.SliceExpr)(nil), (*ast.StarExpr)(nil),
+		(*ast.StructType)(nil), (*ast.SwitchStmt)(nil), (*ast.TypeAssertExpr)(nil), (*ast.TypeSpec)(nil),
+		(*ast.TypeSwitchStmt)(nil), (*ast.UnaryExpr)(nil), (*ast.ValueSpec)(nil),
+	}
+	kinds := make(map[string]ast.Node, len(nodes))
+	for _, n := range nodes {
+		kinds[reflect.TypeOf(n).Elem().Name()] = n
+	}
+	return kinds
+}()
+
+// fileResult holds the issues found by a plugin in a file, keyed by node
+type fileResult struct {
+	issues   map[ast.Node][]Issue
+	err      error
+	reported bool // whether the error was reported as a rule failure
+}
+
+// pluginRule reports the issues found by a plugin for one of its rules
+type pluginRule struct {
+	gosec.MetaData
+	plugin *Plugin
+}
+
+// newRule returns the builder of a rule of the plugin
+func (p *Plugin) newRule(info RuleInfo) gosec.RuleBuilder {
+	return func(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+		nodes := make([]ast.Node, 0, len(p.manifest.Nodes))
+		for _, kind := range p.manifest.Nodes {
+			nodes = append(nodes, nodeKinds[kind])
+		}
+		return &pluginRule{
+			MetaData: gosec.MetaData{
+				ID:         info.ID,
+				What:       info.Description,
+				Severity:   info.Severity,
+				Confidence: info.Confidence,
+			},
+			plugin: p,
+		}, nodes
+	}
+}
+
+func (r *pluginRule) ID() string {
+	return r.MetaData.ID
+}
+
+func (r *pluginRule) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	issues, err := r.MatchAll(n, c)
+	if len(issues) == 0 {
+		return nil, err
+	}
+	return issues[0], err
+}
+
+// MatchAll returns the issues found by the plugin on the node for the rule. An
+// error of the plugin is returned once per file.
+func (r *pluginRule) MatchAll(n ast.Node, c *gosec.Context) ([]*gosec.Issue, error) {
+	result := r.plugin.check(c)
+	if result.err != nil {
+		if result.reported {
+			return nil, nil
+		}
+		result.reported = true
+		return nil, result.err
+	}
+
+	var issues []*gosec.Issue
+	for _, found := range result.issues[n] {
+		if found.RuleID != r.ID() {
+			continue
+		}
+		what, severity, confidence := found.What, r.Severity, r.Confidence
+		if what == "" {
+			what = r.What
+		}
+		if found.Severity != nil {
+			severity = *found.Severity
+		}
+		if found.Confidence != nil {
+			confidence = *found.Confidence
+		}
+		issue := gosec.NewIssue(c, n, r.ID(), what, severity, confidence)
+		if found.CWE != "" {
+			issue.Cwe = cwe.Get(found.CWE)
+			if issue.Cwe == nil {
+				issue.Cwe = &cwe.Weakness{ID: found.CWE}
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// check returns the issues found by the plugin in the file of the context, which
+// is sent to the plugin on the first call for the file. The result is shared by
+// the rules of the plugin through the values passed between the rules, which are
+// cleared for each file.
+func (p *Plugin) check(c *gosec.Context) *fileResult {
+	key := fmt.Sprintf("plugin %s %p", p.name, p)
+	if result, ok := c.PassedValues[key].(*fileResult); ok {
+		return result
+	}
+	result := &fileResult{}
+	result.issues, result.err = p.checkFile(c)
+	if c.PassedValues != nil {
+		c.PassedValues[key] = result
+	}
+	return result
+}
+
+// checkFile sends the nodes of the file to the plugin, and returns its issues
+func (p *Plugin) checkFile(c *gosec.Context) (map[ast.Node][]Issue, error) {
+	kinds := make(map[reflect.Type]string, len(p.manifest.Nodes))
+	for _, kind := range p.manifest.Nodes {
+		kinds[reflect.TypeOf(nodeKinds[kind])] = kind
+	}
+	req := &CheckRequest{
+		Package: Package{Path: c.Pkg.Path(), Name: c.Pkg.Name()},
+		File:    c.FileSet.File(c.Root.Pos()).Name(),
+	}
+	var nodes []ast.Node
+	ast.Inspect(c.Root, func(n ast.Node) bool {
+		if kind, ok := kinds[reflect.TypeOf(n)]; ok {
+			req.Nodes = append(req.Nodes, nodeFacts(len(nodes), kind, n, c))
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+
+	resp, err := p.call(&Request{Method: MethodCheck, Check: req})
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]bool, len(p.manifest.Rules))
+	for _, rule := range p.manifest.Rules {
+		rules[rule.ID] = true
+	}
+	issues := make(map[ast.Node][]Issue)
+	for _, issue := range resp.Issues {
+		if issue.Node < 0 || issue.Node >= len(nodes) {
+			return nil, fmt.Errorf("plugin %s: issue on the unknown node %d", p.name, issue.Node)
+		}
+		if !rules[issue.RuleID] {
+			return nil, fmt.Errorf("plugin %s: issue of the unknown rule %q", p.name, issue.RuleID)
+		}
+		n := nodes[issue.Node]
+		issues[n] = append(issues[n], issue)
+	}
+	return issues, nil
+}
+
+// nodeFacts returns the position of a node and the facts known about it
+func nodeFacts(id int, kind string, n ast.Node, c *gosec.Context) Node {
+	start, end := c.FileSet.Position(n.Pos()), c.FileSet.Position(n.End())
+	node := Node{
+		ID:    id,
+		Kind:  kind,
+		Start: gosec.Position{Line: start.Line, Column: start.Column, Offset: start.Offset},
+		End:   gosec.Position{Line: end.Line, Column: end.Column, Offset: end.Offset},
+	}
+	if expr, ok := n.(ast.Expr); ok {
+		facts := exprFacts(expr, c.Info)
+		node.Text, node.Type, node.Value = facts.Text, facts.Type, facts.Value
+	}
+	if call, ok := n.(*ast.CallExpr); ok {
+		if _, obj := gosec.GetCallObject(call, c); obj != nil {
+			if fn, ok := obj.(*types.Func); ok {
+				node.Callee = fn.FullName()
+			} else {
+				node.Callee = obj.Name()
+			}
+		}
+		for _, arg := range call.Args {
+			node.Args = append(node.Args, exprFacts(arg, c.Info))
+		}
+	}
+	return node
+}
+
+// exprFacts returns the source, the type and the constant value of an expression
+func exprFacts(expr ast.Expr, info *types.Info) Expr {
+	facts := Expr{Text: types.ExprString(expr)}
+	if tv, ok := info.Types[expr]; ok {
+		if tv.Type != nil {
+			facts.Type = types.TypeString(tv.Type, nil)
+		}
+		if tv.Value != nil {
+			facts.Value = tv.Value.ExactString()
+		}
+	}
+	return facts
+}
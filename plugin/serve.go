@@ -0,0 +1,62 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CheckFunc returns the issues found in a file by a plugin
+type CheckFunc func(req *CheckRequest) ([]Issue, error)
+
+// Serve implements the plugin side of the protocol for the plugins written in Go.
+// It reads the requests of gosec from r, usually os.Stdin, and writes the
+// responses to w, usually os.Stdout, until the shutdown request or the end of r.
+// The errors of check are sent to gosec, which reports them as rule failures.
+func Serve(r io.Reader, w io.Writer, manifest *Manifest, check CheckFunc) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	encoder := json.NewEncoder(w)
+	for {
+		var req Request
+		if err := decoder.Decode(&req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		resp := Response{Protocol: ProtocolVersion}
+		switch {
+		case req.Protocol != ProtocolVersion:
+			resp.Error = fmt.Sprintf("unsupported protocol %d, expected %d", req.Protocol, ProtocolVersion)
+		case req.Method == MethodInitialize:
+			resp.Manifest = manifest
+		case req.Method == MethodCheck && req.Check != nil:
+			issues, err := check(req.Check)
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			resp.Issues = issues
+		case req.Method == MethodShutdown:
+			return nil
+		default:
+			resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+		}
+		if err := encoder.Encode(&resp); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"sort"
+	"time"
+)
+
+// Profile holds the execution metrics of the rules and the load time of the
+// packages. The durations are in nanoseconds.
+type Profile struct {
+	Rules    map[string]*RuleProfile `json:"rules"`
+	Packages []*PackageProfile       `json:"packages"`
+}
+
+// RuleProfile counts the invocations of a rule and the time spent in them
+type RuleProfile struct {
+	Calls  int           `json:"calls"`
+	Issues int           `json:"issues"`
+	Total  time.Duration `json:"total"`
+	Max    time.Duration `json:"max"`
+}
+
+// PackageProfile holds the time spent to load and type check the packages of a
+// module, or a package outside of a module
+type PackageProfile struct {
+	Path string        `json:"path"`
+	Load time.Duration `json:"load"`
+}
+
+func newProfile() *Profile {
+	return &Profile{Rules: make(map[string]*RuleProfile)}
+}
+
+// addRule records an invocation of a rule
+func (p *Profile) addRule(ruleID string, elapsed time.Duration, issues int) {
+	rule, ok := p.Rules[ruleID]
+	if !ok {
+		rule = &RuleProfile{}
+		p.Rules[ruleID] = rule
+	}
+	rule.Calls++
+	rule.Issues += issues
+	rule.Total += elapsed
+	if elapsed > rule.Max {
+		rule.Max = elapsed
+	}
+}
+
+// addPackage records the load time of a package
+func (p *Profile) addPackage(pkgPath string, elapsed time.Duration) {
+	p.Packages = append(p.Packages, &PackageProfile{Path: pkgPath, Load: elapsed})
+}
+
+// merge adds the metrics collected in other to the current profile
+func (p *Profile) merge(other *Profile) {
+	for id, rule := range other.Rules {
+		current, ok := p.Rules[id]
+		if !ok {
+			current = &RuleProfile{}
+			p.Rules[id] = current
+		}
+		current.Calls += rule.Calls
+		current.Issues += rule.Issues
+		current.Total += rule.Total
+		if rule.Max > current.Max {
+			current.Max = rule.Max
+		}
+	}
+	p.Packages = append(p.Packages, other.Packages...)
+}
+
+// SortedRules returns the IDs of the profiled rules, the slowest first
+func (p *Profile) SortedRules() []string {
+	ids := make([]string, 0, len(p.Rules))
+	for id := range p.Rules {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if p.Rules[ids[i]].Total == p.Rules[ids[j]].Total {
+			return ids[i] < ids[j]
+		}
+		return p.Rules[ids[i]].Total > p.Rules[ids[j]].Total
+	})
+	return ids
+}
+
+// SortedPackages returns the profiled packages, the slowest first
+func (p *Profile) SortedPackages() []*PackageProfile {
+	pkgs := append([]*PackageProfile{}, p.Packages...)
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].Load > pkgs[j].Load
+	})
+	return pkgs
+}
+
+// profile returns the profile of the current results, which is created on the
+// first use so that the metrics don't hold an empty profile when it's disabled
+func (gosec *Analyzer) profile() *Profile {
+	if gosec.stats.Profile == nil {
+		gosec.stats.Profile = newProfile()
+	}
+	return gosec.stats.Profile
+}
@@ -6,6 +6,8 @@ type ReportInfo struct {
 	Issues       []*Issue
 	Stats        *Metrics
 	GosecVersion string
+	Suppressed   []*Issue       `json:"suppressed,omitempty" yaml:"suppressed,omitempty"`
+	RuleFailures []*RuleFailure `json:"rule_failures,omitempty" yaml:"rule_failures,omitempty"`
 }
 
 // NewReportInfo instantiate a ReportInfo
@@ -17,6 +19,18 @@ func NewReportInfo(issues []*Issue, metrics *Metrics, errors map[string][]Error)
 	}
 }
 
+// WithSuppressed defines the issues suppressed by #nosec directives
+func (r *ReportInfo) WithSuppressed(suppressed []*Issue) *ReportInfo {
+	r.Suppressed = suppressed
+	return r
+}
+
+// WithRuleFailures defines the errors and the panics of the rules
+func (r *ReportInfo) WithRuleFailures(failures []*RuleFailure) *ReportInfo {
+	r.RuleFailures = failures
+	return r
+}
+
 // WithVersion defines the version of gosec used to generate the report
 func (r *ReportInfo) WithVersion(version string) *ReportInfo {
 	r.GosecVersion = version
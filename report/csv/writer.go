@@ -3,11 +3,13 @@ package csv
 import (
 	"encoding/csv"
 	"io"
+	"strconv"
 
 	"github.com/securego/gosec/v2"
 )
 
-// WriteReport write a report in csv format to the output writer
+// WriteReport write a report in csv format to the output writer. The rule
+// failures follow the issues, in rows starting with "rule failure".
 func WriteReport(w io.Writer, data *gosec.ReportInfo) error {
 	out := csv.NewWriter(w)
 	defer out.Flush()
@@ -20,6 +22,21 @@ func WriteReport(w io.Writer, data *gosec.ReportInfo) error {
 			issue.Confidence.String(),
 			issue.Code,
 			issue.Cwe.SprintID(),
+			issue.Fingerprint,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, failure := range data.RuleFailures {
+		err := out.Write([]string{
+			"rule failure",
+			failure.RuleID,
+			failure.File,
+			strconv.Itoa(failure.Line),
+			strconv.Itoa(failure.Column),
+			failure.Err,
+			failure.Stack,
 		})
 		if err != nil {
 			return err
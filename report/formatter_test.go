@@ -24,15 +24,16 @@ func createIssueWithFileWhat(file, what string) *gosec.Issue {
 
 func createIssue(ruleID string, weakness *cwe.Weakness) gosec.Issue {
 	return gosec.Issue{
-		File:       "/home/src/project/test.go",
-		Line:       "1",
-		Col:        "1",
-		RuleID:     ruleID,
-		What:       "test",
-		Confidence: gosec.High,
-		Severity:   gosec.High,
-		Code:       "1: testcode",
-		Cwe:        weakness,
+		File:        "/home/src/project/test.go",
+		Line:        "1",
+		Col:         "1",
+		RuleID:      ruleID,
+		What:        "test",
+		Confidence:  gosec.High,
+		Severity:    gosec.High,
+		Code:        "1: testcode",
+		Cwe:         weakness,
+		Fingerprint: "fingerprint",
 	}
 }
 
@@ -292,11 +293,36 @@ var _ = Describe("Formatter", func() {
 				reportInfo := gosec.NewReportInfo([]*gosec.Issue{&issue}, &gosec.Metrics{}, error)
 				err := CreateReport(buf, "csv", false, []string{}, reportInfo)
 				Expect(err).ShouldNot(HaveOccurred())
-				pattern := "/home/src/project/test.go,1,test,HIGH,HIGH,1: testcode,CWE-%s\n"
+				pattern := "/home/src/project/test.go,1,test,HIGH,HIGH,1: testcode,CWE-%s,fingerprint\n"
 				expect := fmt.Sprintf(pattern, cwe.ID)
 				Expect(buf.String()).To(Equal(expect))
 			}
 		})
+		It("csv formatted report should contain the rule failures", func() {
+			issue := createIssue("G101", gosec.GetCweByRule("G101"))
+			failure := &gosec.RuleFailure{RuleID: "G102", File: "/home/src/project/test.go", Line: 3, Column: 5, Err: "panic: boom"}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{&issue}, &gosec.Metrics{}, map[string][]gosec.Error{}).
+				WithRuleFailures([]*gosec.RuleFailure{failure})
+
+			buf := new(bytes.Buffer)
+			err := CreateReport(buf, "csv", false, []string{}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(buf.String()).To(Equal("/home/src/project/test.go,1,test,HIGH,HIGH,1: testcode,CWE-798,fingerprint\n" +
+				"rule failure,G102,/home/src/project/test.go,3,5,panic: boom,\n"))
+		})
+		It("sonarqube formatted report should contain the rule failures", func() {
+			failure := &gosec.RuleFailure{RuleID: "G102", File: "/home/src/project/test.go", Line: 3, Column: 5, Err: "panic: boom"}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{}, &gosec.Metrics{}, map[string][]gosec.Error{}).
+				WithRuleFailures([]*gosec.RuleFailure{failure})
+
+			buf := new(bytes.Buffer)
+			err := CreateReport(buf, "sonarqube", false, []string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			result := stripString(buf.String())
+			Expect(result).To(ContainSubstring(`"ruleId":"G102"`))
+			Expect(result).To(ContainSubstring(`"message":"Rulefailure:panic:boom","filePath":"test.go","textRange":{"startLine":3,"endLine":3}`))
+			Expect(result).To(ContainSubstring(`"severity":"INFO"`))
+		})
 		It("xml formatted report should contain the CWE mapping", func() {
 			for _, rule := range grules {
 				cwe := gosec.GetCweByRule(rule)
@@ -453,6 +479,17 @@ var _ = Describe("Formatter", func() {
 				Expect(buf.String()).To(Equal(expect))
 			}
 		})
+		It("golint formatted report should keep the issues with an invalid line", func() {
+			issue := createIssue("G101", gosec.GetCweByRule("G101"))
+			issue.Line = "?"
+			issue.Col = ""
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{&issue}, &gosec.Metrics{}, map[string][]gosec.Error{})
+
+			buf := new(bytes.Buffer)
+			err := CreateReport(buf, "golint", false, []string{}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(buf.String()).To(HavePrefix("/home/src/project/test.go:?:: [CWE-798] test (Rule:G101"))
+		})
 		It("sarif formatted report should contain the CWE mapping", func() {
 			for _, rule := range grules {
 				cwe := gosec.GetCweByRule(rule)
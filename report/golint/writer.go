@@ -3,6 +3,7 @@ package golint
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/securego/gosec/v2"
@@ -19,14 +20,17 @@ func WriteReport(w io.Writer, data *gosec.ReportInfo) error {
 			what = fmt.Sprintf("[%s] %s", issue.Cwe.SprintID(), issue.What)
 		}
 
-		// issue.Line uses "start-end" format for multiple line detection.
-		lines := strings.Split(issue.Line, "-")
-		start := lines[0]
+		// issue.Line uses "start-end" format for multiple line detection. The line
+		// and the column are written as is when they can't be parsed.
+		line, col := strings.Split(issue.Line, "-")[0], issue.Col
+		if start, _, err := issue.Range(); err == nil {
+			line, col = strconv.Itoa(start.Line), strconv.Itoa(start.Column)
+		}
 
 		_, err := fmt.Fprintf(w, "%s:%s:%s: %s (Rule:%s, Severity:%s, Confidence:%s)\n",
 			issue.File,
-			start,
-			issue.Col,
+			line,
+			col,
 			what,
 			issue.RuleID,
 			issue.Severity.String(),
@@ -36,5 +40,20 @@ func WriteReport(w io.Writer, data *gosec.ReportInfo) error {
 			return err
 		}
 	}
+
+	// Output Sample:
+	// /tmp/main.go:11:14: rule failure: panic: runtime error: index out of range [0] with length 0 (Rule:G107)
+	for _, failure := range data.RuleFailures {
+		_, err := fmt.Fprintf(w, "%s:%d:%d: rule failure: %s (Rule:%s)\n",
+			failure.File,
+			failure.Line,
+			failure.Column,
+			failure.Err,
+			failure.RuleID,
+		)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
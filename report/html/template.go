@@ -93,6 +93,7 @@ const templateContent = `
               <div className="column is-three-quarters">
                 <strong className="break-word">{ this.props.data.file } (line { this.props.data.line })</strong>
                 <p>{ this.props.data.details }</p>
+                { this.props.data.suppressions ? <p className="help">Suppressed: { this.props.data.suppressions.map(function(s) { return s.justification || 'no justification'; }).join(', ') }</p> : null }
               </div>
               <div className="column is-one-quarter">
                 <div className="field is-grouped is-grouped-multiline">
@@ -115,6 +116,7 @@ const templateContent = `
             Gosec {this.props.data.GosecVersion} scanned { this.props.data.Stats.files.toLocaleString() } files
             with { this.props.data.Stats.lines.toLocaleString() } lines of code.
             { this.props.data.Stats.nosec ? '\n' + this.props.data.Stats.nosec.toLocaleString() + ' false positives (nosec) have been waived.' : ''}
+            { this.props.data.Stats.baseline ? '\n' + this.props.data.Stats.baseline.unchanged.toLocaleString() + ' known issues (baseline) have been waived, ' + this.props.data.Stats.baseline.fixed.toLocaleString() + ' have been fixed.' : ''}
           </p>
         );
       }
@@ -174,6 +176,47 @@ const templateContent = `
         );
       }
     });
+    var Suppressed = React.createClass({
+      render: function() {
+        if (!this.props.data.suppressed || this.props.data.suppressed.length === 0) {
+          return null;
+        }
+        var issues = this.props.data.suppressed
+          .map(function(issue) {
+            return (<Issue data={issue} />);
+          });
+        return (
+          <div className="suppressed">
+            <h2 className="subtitle">Suppressed issues</h2>
+            { issues }
+          </div>
+        );
+      }
+    });
+    var RuleFailures = React.createClass({
+      render: function() {
+        if (!this.props.data.rule_failures || this.props.data.rule_failures.length === 0) {
+          return null;
+        }
+        var failures = this.props.data.rule_failures
+          .map(function(failure) {
+            return (
+              <div className="notification is-danger">
+                <strong className="break-word">
+                  Rule { failure.rule_id } failed{ failure.file ? ' in ' + failure.file + ' (line ' + failure.line + ')' : '' }: { failure.error }
+                </strong>
+                { failure.stack ? <pre className="break-word">{ failure.stack }</pre> : null }
+              </div>
+            );
+          });
+        return (
+          <div className="rule-failures">
+            <h2 className="subtitle">Rule failures</h2>
+            { failures }
+          </div>
+        );
+      }
+    });
     var LevelSelector = React.createClass({
       handleChange: function(level) {
         return function(e) {
@@ -390,6 +433,8 @@ const templateContent = `
                   confidence={ this.state.confidence }
                   issueType={ this.state.issueType }
                 />
+                <Suppressed data={ this.props.data } />
+                <RuleFailures data={ this.props.data } />
               </div>
             </div>
           </div>
@@ -15,6 +15,14 @@ func NewFailure(message string, text string) *Failure {
 	}
 }
 
+// NewError instantiate an Error
+func NewError(message string, text string) *Error {
+	return &Error{
+		Message: message,
+		Text:    text,
+	}
+}
+
 // NewTestcase instantiate a Testcase
 func NewTestcase(name string, failure *Failure) *Testcase {
 	return &Testcase{
@@ -22,3 +30,9 @@ func NewTestcase(name string, failure *Failure) *Testcase {
 		Failure: failure,
 	}
 }
+
+// WithError defines the error of the Testcase
+func (t *Testcase) WithError(err *Error) *Testcase {
+	t.Error = err
+	return t
+}
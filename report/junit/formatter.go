@@ -34,5 +34,17 @@ func GenerateReport(data *gosec.ReportInfo) Report {
 		xmlReport.Testsuites[index].Tests++
 	}
 
+	if len(data.RuleFailures) > 0 {
+		testsuite := NewTestsuite("Rule failures")
+		for _, failure := range data.RuleFailures {
+			message := "Rule " + failure.RuleID + " failed: " + failure.Err
+			location := failure.File + ":" + strconv.Itoa(failure.Line)
+			err := NewError(message, html.EscapeString(location+"\n"+failure.Stack))
+			testsuite.Testcases = append(testsuite.Testcases, NewTestcase(failure.File, nil).WithError(err))
+			testsuite.Tests++
+		}
+		xmlReport.Testsuites = append(xmlReport.Testsuites, testsuite)
+	}
+
 	return xmlReport
 }
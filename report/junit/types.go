@@ -23,6 +23,14 @@ type Testcase struct {
 	XMLName xml.Name `xml:"testcase"`
 	Name    string   `xml:"name,attr"`
 	Failure *Failure `xml:"failure"`
+	Error   *Error   `xml:"error"`
+}
+
+// Error defines a JUnit error
+type Error struct {
+	XMLName xml.Name `xml:"error"`
+	Message string   `xml:"message,attr"`
+	Text    string   `xml:",innerxml"`
 }
 
 // Failure defines a JUnit failure
@@ -40,6 +40,60 @@ func (r *Run) WithResults(results ...*Result) *Run {
 	return r
 }
 
+// WithInvocations set the invocations for the current run
+func (r *Run) WithInvocations(invocations ...*Invocation) *Run {
+	r.Invocations = invocations
+	return r
+}
+
+// NewInvocation instantiate an Invocation
+func NewInvocation(executionSuccessful bool) *Invocation {
+	return &Invocation{
+		ExecutionSuccessful: executionSuccessful,
+	}
+}
+
+// WithToolExecutionNotifications define the notifications raised while running the tool
+func (i *Invocation) WithToolExecutionNotifications(notifications ...*Notification) *Invocation {
+	i.ToolExecutionNotifications = notifications
+	return i
+}
+
+// NewNotification instantiate a Notification associated with a rule
+func NewNotification(ruleID string, level Level, message string) *Notification {
+	return &Notification{
+		AssociatedRule: &ReportingDescriptorReference{ID: ruleID},
+		Level:          level,
+		Message:        NewMessage(message),
+	}
+}
+
+// WithLocations define the current notification's locations
+func (n *Notification) WithLocations(locations ...*Location) *Notification {
+	n.Locations = locations
+	return n
+}
+
+// WithException define the exception of the current notification
+func (n *Notification) WithException(exception *Exception) *Notification {
+	n.Exception = exception
+	return n
+}
+
+// NewException instantiate an Exception
+func NewException(kind string, message string) *Exception {
+	return &Exception{
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+// WithProperties define the properties of the current exception
+func (e *Exception) WithProperties(properties PropertyBag) *Exception {
+	e.Properties = &properties
+	return e
+}
+
 // NewArtifactLocation instantiate an ArtifactLocation
 func NewArtifactLocation(uri string) *ArtifactLocation {
 	return &ArtifactLocation{
@@ -64,6 +118,13 @@ func (r *Region) WithSnippet(snippet *ArtifactContent) *Region {
 	return r
 }
 
+// WithByteRange defines the offset and the length in bytes of the region
+func (r *Region) WithByteRange(offset int, length int) *Region {
+	r.ByteOffset = offset
+	r.ByteLength = length
+	return r
+}
+
 // NewArtifactContent instantiate an ArtifactContent
 func NewArtifactContent(text string) *ArtifactContent {
 	return &ArtifactContent{
@@ -101,6 +162,32 @@ func (r *Result) WithLocations(locations ...*Location) *Result {
 	return r
 }
 
+// WithPartialFingerprints define the current result's partial fingerprints
+func (r *Result) WithPartialFingerprints(fingerprints map[string]string) *Result {
+	r.PartialFingerprints = fingerprints
+	return r
+}
+
+// WithProperties define the current result's properties
+func (r *Result) WithProperties(properties PropertyBag) *Result {
+	r.Properties = &properties
+	return r
+}
+
+// WithSuppressions define the current result's suppressions
+func (r *Result) WithSuppressions(suppressions ...*Suppression) *Result {
+	r.Suppressions = suppressions
+	return r
+}
+
+// NewSuppression instantiate a Suppression
+func NewSuppression(kind string, justification string) *Suppression {
+	return &Suppression{
+		Kind:          kind,
+		Justification: justification,
+	}
+}
+
 // NewLocation instantiate a Location
 func NewLocation(physicalLocation *PhysicalLocation) *Location {
 	return &Location{
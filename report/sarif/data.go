@@ -19,4 +19,6 @@ const (
 	Version = "2.1.0"
 	// Schema : SARIF Schema URL
 	Schema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	// FingerprintKey : key of the gosec issue fingerprint in the partial fingerprints of a result
+	FingerprintKey = "gosecFingerprint/v1"
 )
@@ -3,7 +3,6 @@ package sarif
 import (
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -27,10 +26,15 @@ func GenerateReport(rootPaths []string, data *gosec.ReportInfo) (*Report, error)
 	cweTaxa := make([]*ReportingDescriptor, 0)
 	weaknesses := make(map[string]*cwe.Weakness)
 
-	for _, issue := range data.Issues {
+	issues := append(append([]*gosec.Issue{}, data.Issues...), data.Suppressed...)
+	for _, issue := range issues {
 		_, ok := weaknesses[issue.Cwe.ID]
 		if !ok {
 			weakness := cwe.Get(issue.Cwe.ID)
+			if weakness == nil {
+				// the weakness of a registered rule might be unknown to gosec
+				weakness = issue.Cwe
+			}
 			weaknesses[issue.Cwe.ID] = weakness
 			cweTaxon := parseSarifTaxon(weakness)
 			cweTaxa = append(cweTaxa, cweTaxon)
@@ -51,6 +55,15 @@ func GenerateReport(rootPaths []string, data *gosec.ReportInfo) (*Report, error)
 
 		result := NewResult(r.rule.ID, r.index, getSarifLevel(issue.Severity.String()), issue.What).
 			WithLocations(location)
+		if issue.Fingerprint != "" {
+			result.WithPartialFingerprints(map[string]string{FingerprintKey: issue.Fingerprint})
+		}
+		if len(issue.Suppressions) > 0 {
+			result.WithSuppressions(parseSarifSuppressions(issue)...)
+		}
+		if issue.Generated {
+			result.WithProperties(PropertyBag{"generated": true})
+		}
 
 		results = append(results, result)
 	}
@@ -65,11 +78,24 @@ func GenerateReport(rootPaths []string, data *gosec.ReportInfo) (*Report, error)
 	run := NewRun(tool).
 		WithTaxonomies(cweTaxonomy).
 		WithResults(results...)
+	if len(data.RuleFailures) > 0 {
+		run.WithInvocations(NewInvocation(false).
+			WithToolExecutionNotifications(parseSarifNotifications(data.RuleFailures, rootPaths)...))
+	}
 
 	return NewReport(Version, Schema).
 		WithRuns(run), nil
 }
 
+// parseSarifSuppressions return SARIF suppressions of the issue
+func parseSarifSuppressions(issue *gosec.Issue) []*Suppression {
+	suppressions := make([]*Suppression, 0, len(issue.Suppressions))
+	for _, s := range issue.Suppressions {
+		suppressions = append(suppressions, NewSuppression(s.Kind, s.Justification))
+	}
+	return suppressions
+}
+
 // parseSarifRule return SARIF rule field struct
 func parseSarifRule(issue *gosec.Issue) *ReportingDescriptor {
 	return &ReportingDescriptor{
@@ -153,39 +179,55 @@ func parseSarifLocation(issue *gosec.Issue, rootPaths []string) (*Location, erro
 	if err != nil {
 		return nil, err
 	}
-	artifactLocation := parseSarifArtifactLocation(issue, rootPaths)
+	artifactLocation := parseSarifArtifactLocation(issue.File, rootPaths)
 	return NewLocation(NewPhysicalLocation(artifactLocation, region)), nil
 }
 
-func parseSarifArtifactLocation(issue *gosec.Issue, rootPaths []string) *ArtifactLocation {
+func parseSarifArtifactLocation(file string, rootPaths []string) *ArtifactLocation {
 	var filePath string
 	for _, rootPath := range rootPaths {
-		if strings.HasPrefix(issue.File, rootPath) {
-			filePath = strings.Replace(issue.File, rootPath+"/", "", 1)
+		if strings.HasPrefix(file, rootPath) {
+			filePath = strings.Replace(file, rootPath+"/", "", 1)
 		}
 	}
 	return NewArtifactLocation(filePath)
 }
 
-func parseSarifRegion(issue *gosec.Issue) (*Region, error) {
-	lines := strings.Split(issue.Line, "-")
-	startLine, err := strconv.Atoi(lines[0])
-	if err != nil {
-		return nil, err
-	}
-	endLine := startLine
-	if len(lines) > 1 {
-		endLine, err = strconv.Atoi(lines[1])
-		if err != nil {
-			return nil, err
+// parseSarifNotifications return the SARIF notifications of the rule failures
+func parseSarifNotifications(failures []*gosec.RuleFailure, rootPaths []string) []*Notification {
+	notifications := make([]*Notification, 0, len(failures))
+	for _, failure := range failures {
+		exception := NewException("error", failure.Err)
+		if failure.Stack != "" {
+			exception = NewException("panic", failure.Err).WithProperties(PropertyBag{"stack": failure.Stack})
 		}
+		notification := NewNotification(failure.RuleID, Error, fmt.Sprintf("Rule %s failed: %s", failure.RuleID, failure.Err)).
+			WithException(exception)
+		if failure.File != "" {
+			region := NewRegion(failure.Line, failure.Line, failure.Column, failure.Column, "go")
+			notification.WithLocations(NewLocation(NewPhysicalLocation(parseSarifArtifactLocation(failure.File, rootPaths), region)))
+		}
+		notifications = append(notifications, notification)
 	}
-	col, err := strconv.Atoi(issue.Col)
+	return notifications
+}
+
+func parseSarifRegion(issue *gosec.Issue) (*Region, error) {
+	start, end, err := issue.Range()
 	if err != nil {
 		return nil, err
 	}
+	endColumn := end.Column
+	if endColumn == 0 {
+		// the end of the issue is unknown
+		endColumn = start.Column
+	}
 	snippet := NewArtifactContent(issue.Code)
-	return NewRegion(startLine, endLine, col, col, "go").WithSnippet(snippet), nil
+	region := NewRegion(start.Line, end.Line, start.Column, endColumn, "go").WithSnippet(snippet)
+	if end.Offset > start.Offset {
+		region = region.WithByteRange(start.Offset, end.Offset-start.Offset)
+	}
+	return region, nil
 }
 
 func getSarifLevel(s string) Level {
@@ -21,5 +21,114 @@ var _ = Describe("Sarif Formatter", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(result).To(ContainSubstring("\"results\": ["))
 		})
+
+		It("sarif formatted report should contain the suppressed issues", func() {
+			issue := &gosec.Issue{
+				Severity:     gosec.High,
+				Confidence:   gosec.High,
+				Cwe:          gosec.GetCweByRule("G101"),
+				RuleID:       "G101",
+				What:         "test",
+				File:         "/home/src/project/test.go",
+				Code:         "1: testcode",
+				Line:         "1",
+				Col:          "1",
+				Suppressions: []gosec.SuppressionInfo{{Kind: gosec.InSource, Justification: "test credentials"}},
+			}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{}, &gosec.Metrics{}, map[string][]gosec.Error{}).
+				WithSuppressed([]*gosec.Issue{issue}).
+				WithVersion("v2.7.0")
+			report, err := sarif.GenerateReport([]string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(report.Runs[0].Results).To(HaveLen(1))
+			Expect(report.Runs[0].Results[0].Suppressions).To(Equal([]*sarif.Suppression{
+				{Kind: gosec.InSource, Justification: "test credentials"},
+			}))
+		})
+
+		It("sarif formatted report should reference the weaknesses unknown to gosec", func() {
+			gosec.RegisterCwe("X201", "705")
+			issue := &gosec.Issue{
+				Severity:   gosec.Low,
+				Confidence: gosec.High,
+				Cwe:        gosec.GetCweByRule("X201"),
+				RuleID:     "X201",
+				What:       "test",
+				File:       "/home/src/project/test.go",
+				Code:       "1: testcode",
+				Line:       "1",
+				Col:        "1",
+			}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{issue}, &gosec.Metrics{}, map[string][]gosec.Error{}).WithVersion("v2.7.0")
+			report, err := sarif.GenerateReport([]string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(report.Runs[0].Taxonomies[0].Taxa).To(HaveLen(1))
+			Expect(report.Runs[0].Taxonomies[0].Taxa[0].ID).To(Equal("705"))
+			Expect(report.Runs[0].Tool.Driver.Rules[0].Relationships[0].Target.ID).To(Equal("705"))
+		})
+
+		It("sarif formatted report should contain the fingerprint of the issues", func() {
+			issue := &gosec.Issue{
+				Severity:    gosec.High,
+				Confidence:  gosec.High,
+				Cwe:         gosec.GetCweByRule("G101"),
+				RuleID:      "G101",
+				What:        "test",
+				File:        "/home/src/project/test.go",
+				Code:        "1: testcode",
+				Line:        "1",
+				Col:         "1",
+				Fingerprint: "fingerprint",
+			}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{issue}, &gosec.Metrics{}, map[string][]gosec.Error{}).WithVersion("v2.7.0")
+			report, err := sarif.GenerateReport([]string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(report.Runs[0].Results[0].PartialFingerprints).To(Equal(map[string]string{sarif.FingerprintKey: "fingerprint"}))
+		})
+
+		It("sarif formatted report should mark the issues of the generated files", func() {
+			issue := &gosec.Issue{
+				Severity:   gosec.Low,
+				Confidence: gosec.High,
+				Cwe:        gosec.GetCweByRule("G101"),
+				RuleID:     "G101",
+				What:       "test",
+				File:       "/home/src/project/test.pb.go",
+				Code:       "1: testcode",
+				Line:       "1",
+				Col:        "1",
+				Generated:  true,
+			}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{issue}, &gosec.Metrics{}, map[string][]gosec.Error{}).WithVersion("v2.7.0")
+			report, err := sarif.GenerateReport([]string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(report.Runs[0].Results[0].Properties).To(Equal(&sarif.PropertyBag{"generated": true}))
+		})
+
+		It("sarif formatted report should contain the exact region of the issues", func() {
+			issue := &gosec.Issue{
+				Severity:   gosec.High,
+				Confidence: gosec.High,
+				Cwe:        gosec.GetCweByRule("G101"),
+				RuleID:     "G101",
+				What:       "test",
+				File:       "/home/src/project/test.go",
+				Code:       "2: testcode",
+				Line:       "2",
+				Col:        "5",
+				Start:      gosec.Position{Line: 2, Column: 5, Offset: 17},
+				End:        gosec.Position{Line: 2, Column: 13, Offset: 25},
+			}
+			reportInfo := gosec.NewReportInfo([]*gosec.Issue{issue}, &gosec.Metrics{}, map[string][]gosec.Error{}).WithVersion("v2.7.0")
+			report, err := sarif.GenerateReport([]string{"/home/src/project"}, reportInfo)
+			Expect(err).ShouldNot(HaveOccurred())
+			region := report.Runs[0].Results[0].Locations[0].PhysicalLocation.Region
+			Expect(region.StartLine).To(Equal(2))
+			Expect(region.EndLine).To(Equal(2))
+			Expect(region.StartColumn).To(Equal(5))
+			Expect(region.EndColumn).To(Equal(13))
+			Expect(region.ByteOffset).To(Equal(17))
+			Expect(region.ByteLength).To(Equal(8))
+		})
 	})
 })
@@ -17,6 +17,13 @@ func NewTextRange(startLine int, endLine int) *TextRange {
 	}
 }
 
+// WithColumns defines the start and the end columns of the TextRange
+func (t *TextRange) WithColumns(startColumn int, endColumn int) *TextRange {
+	t.StartColumn = startColumn
+	t.EndColumn = endColumn
+	return t
+}
+
 // NewIssue instantiate an Issue
 func NewIssue(engineID string, ruleID string, primaryLocation *Location, issueType string, severity string, effortMinutes int) *Issue {
 	return &Issue{
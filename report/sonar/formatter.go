@@ -1,7 +1,7 @@
 package sonar
 
 import (
-	"strconv"
+	"fmt"
 	"strings"
 
 	"github.com/securego/gosec/v2"
@@ -31,8 +31,24 @@ func GenerateReport(rootPaths []string, data *gosec.ReportInfo) (*Report, error)
 		severity := getSonarSeverity(issue.Severity.String())
 
 		s := NewIssue("gosec", issue.RuleID, primaryLocation, "VULNERABILITY", severity, EffortMinutes)
+		s.Key = issue.Fingerprint
 		si.Issues = append(si.Issues, s)
 	}
+	// the rule failures are reported as the issues of the failed rules, since a
+	// sonar report holds only issues
+	for _, failure := range data.RuleFailures {
+		sonarFilePath := parseFilePath(&gosec.Issue{File: failure.File}, rootPaths)
+		if sonarFilePath == "" {
+			continue
+		}
+		var textRange *TextRange
+		if failure.Line > 0 {
+			textRange = NewTextRange(failure.Line, failure.Line)
+		}
+		message := fmt.Sprintf("Rule failure: %s", failure.Err)
+		primaryLocation := NewLocation(message, sonarFilePath, textRange)
+		si.Issues = append(si.Issues, NewIssue("gosec", failure.RuleID, primaryLocation, "BUG", "INFO", 0))
+	}
 	return si, nil
 }
 
@@ -47,19 +63,16 @@ func parseFilePath(issue *gosec.Issue, rootPaths []string) string {
 }
 
 func parseTextRange(issue *gosec.Issue) (*TextRange, error) {
-	lines := strings.Split(issue.Line, "-")
-	startLine, err := strconv.Atoi(lines[0])
+	start, end, err := issue.Range()
 	if err != nil {
 		return nil, err
 	}
-	endLine := startLine
-	if len(lines) > 1 {
-		endLine, err = strconv.Atoi(lines[1])
-		if err != nil {
-			return nil, err
-		}
+	textRange := NewTextRange(start.Line, end.Line)
+	if start.Column > 0 && end.Column > 0 {
+		// the columns are 0-based in sonar
+		textRange = textRange.WithColumns(start.Column-1, end.Column-1)
 	}
-	return NewTextRange(startLine, endLine), nil
+	return textRange, nil
 }
 
 func getSonarSeverity(s string) string {
@@ -59,6 +59,61 @@ var _ = Describe("Sonar Formatter", func() {
 			Expect(*issues).To(Equal(*want))
 		})
 
+		It("it should use the fingerprint of the issue as key", func() {
+			data := &gosec.ReportInfo{
+				Errors: map[string][]gosec.Error{},
+				Issues: []*gosec.Issue{
+					{
+						Severity:    2,
+						Confidence:  0,
+						RuleID:      "test",
+						What:        "test",
+						File:        "/home/src/project/test.go",
+						Code:        "",
+						Line:        "1",
+						Fingerprint: "fingerprint",
+					},
+				},
+				Stats: &gosec.Metrics{},
+			}
+
+			issues, err := sonar.GenerateReport([]string{"/home/src/project"}, data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(issues.Issues).To(HaveLen(1))
+			Expect(issues.Issues[0].Key).To(Equal("fingerprint"))
+		})
+
+		It("it should use the columns of the issue in the text range", func() {
+			data := &gosec.ReportInfo{
+				Errors: map[string][]gosec.Error{},
+				Issues: []*gosec.Issue{
+					{
+						Severity:   2,
+						Confidence: 0,
+						RuleID:     "test",
+						What:       "test",
+						File:       "/home/src/project/test.go",
+						Code:       "",
+						Line:       "1-2",
+						Col:        "3",
+						Start:      gosec.Position{Line: 1, Column: 3, Offset: 2},
+						End:        gosec.Position{Line: 2, Column: 8, Offset: 20},
+					},
+				},
+				Stats: &gosec.Metrics{},
+			}
+
+			issues, err := sonar.GenerateReport([]string{"/home/src/project"}, data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(issues.Issues).To(HaveLen(1))
+			Expect(issues.Issues[0].PrimaryLocation.TextRange).To(Equal(&sonar.TextRange{
+				StartLine:   1,
+				EndLine:     2,
+				StartColumn: 2,
+				EndColumn:   7,
+			}))
+		})
+
 		It("it should parse the report info with files in subfolders", func() {
 			data := &gosec.ReportInfo{
 				Errors: map[string][]gosec.Error{},
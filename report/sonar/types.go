@@ -5,7 +5,7 @@ type TextRange struct {
 	StartLine   int `json:"startLine"`
 	EndLine     int `json:"endLine"`
 	StartColumn int `json:"startColumn,omitempty"`
-	EtartColumn int `json:"endColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
 }
 
 // Location defines a sonar issue's location
@@ -17,6 +17,7 @@ type Location struct {
 
 // Issue defines a sonar issue
 type Issue struct {
+	Key                string      `json:"key,omitempty"`
 	EngineID           string      `json:"engineId"`
 	RuleID             string      `json:"ruleId"`
 	PrimaryLocation    *Location   `json:"primaryLocation"`
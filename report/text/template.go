@@ -7,8 +7,14 @@ Golang errors in file: [{{ $filePath }}]:
   > [line {{$error.Line}} : column {{$error.Column}}] - {{$error.Err}}
 {{end}}
 {{end}}
+{{- range $index, $failure := .RuleFailures }}
+Rule failure: [{{ $failure.RuleID }}] in [{{ $failure.File }}:{{ $failure.Line }}:{{ $failure.Column }}] - {{ $failure.Err }}
+{{ if $failure.Stack }}{{ $failure.Stack }}{{ end }}
+{{ end }}
 {{ range $index, $issue := .Issues }}
 [{{ highlight $issue.FileLocation $issue.Severity }}] - {{ $issue.RuleID }} ({{ $issue.Cwe.SprintID }}): {{ $issue.What }} (Confidence: {{ $issue.Confidence}}, Severity: {{ $issue.Severity }})
+{{- if $issue.Generated }} [generated]{{ end }}
+{{- if $issue.Platforms }} [{{ range $i, $platform := $issue.Platforms }}{{ if $i }}, {{ end }}{{ $platform }}{{ end }}]{{ end }}
 {{ printCode $issue }}
 
 {{ end }}
@@ -16,11 +22,20 @@ Golang errors in file: [{{ $filePath }}]:
   Gosec  : {{.GosecVersion}}
   Files  : {{.Stats.NumFiles}}
   Lines  : {{.Stats.NumLines}}
+{{- if .Stats.NumGenerated }}
+  Generated : {{.Stats.NumGenerated}}
+{{- end }}
   Nosec  : {{.Stats.NumNosec}}
   Issues : {{ if eq .Stats.NumFound 0 }}
 	{{- success .Stats.NumFound }}
 	{{- else }}
 	{{- danger .Stats.NumFound }}
 	{{- end }}
+{{- if .RuleFailures }}
+  Rule failures : {{ danger (len .RuleFailures) }}
+{{- end }}
+{{- if .Stats.Baseline }}
+  Baseline : {{.Stats.Baseline.New}} new, {{.Stats.Baseline.Unchanged}} unchanged, {{.Stats.Baseline.Fixed}} fixed
+{{- end }}
 
 `
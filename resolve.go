@@ -14,18 +14,195 @@
 
 package gosec
 
-import "go/ast"
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// resolveCache memoizes the resolution of the functions across a load of packages,
+// along with the values assigned to the variables after their declaration. The
+// functions are keyed by their object, which is unique to the load, hence to the
+// platform and to the overlay of the load. All the loaded packages are registered
+// before any of them is checked, so that the calls to the functions of the other
+// packages of the load are resolved regardless of the order of the checks.
+type resolveCache struct {
+	mu       sync.Mutex
+	resolved map[*types.Func]bool
+	assigned map[*types.Package]map[types.Object][]ast.Expr
+	packages map[*types.Package]*packages.Package
+}
+
+func newResolveCache() *resolveCache {
+	return &resolveCache{
+		resolved: make(map[*types.Func]bool),
+		assigned: make(map[*types.Package]map[types.Object][]ast.Expr),
+		packages: make(map[*types.Package]*packages.Package),
+	}
+}
+
+func (rc *resolveCache) get(fn *types.Func) (bool, bool) {
+	if rc == nil {
+		return false, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	resolved, ok := rc.resolved[fn]
+	return resolved, ok
+}
+
+func (rc *resolveCache) set(fn *types.Func, resolved bool) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.resolved[fn] = resolved
+}
+
+// addPackages registers the loaded packages
+func (rc *resolveCache) addPackages(pkgs []*packages.Package) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, pkg := range pkgs {
+		if pkg.Types != nil && pkg.TypesInfo != nil {
+			rc.packages[pkg.Types] = pkg
+		}
+	}
+}
+
+// lookupPackage returns the loaded package of the type-checked package, nil when
+// it was not loaded with its syntax
+func (rc *resolveCache) lookupPackage(pkg *types.Package) *packages.Package {
+	if rc == nil {
+		return nil
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.packages[pkg]
+}
+
+// assignedVars returns the values assigned to the variables of the package of the
+// context after their declaration
+func (rc *resolveCache) assignedVars(c *Context) map[types.Object][]ast.Expr {
+	if rc == nil {
+		return findAssignedVars(c.Info, c.PkgFiles)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	assigned, ok := rc.assigned[c.Pkg]
+	if !ok {
+		assigned = findAssignedVars(c.Info, c.PkgFiles)
+		rc.assigned[c.Pkg] = assigned
+	}
+	return assigned
+}
+
+// clear removes the functions and the packages of the previous load
+func (rc *resolveCache) clear() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.resolved = make(map[*types.Func]bool)
+	rc.assigned = make(map[*types.Package]map[types.Object][]ast.Expr)
+	rc.packages = make(map[*types.Package]*packages.Package)
+}
+
+// findAssignedVars returns the values assigned to the variables in the files,
+// besides their declaration. The value is nil when it's unknown, e.g. when the
+// variable is incremented, assigned by a range loop or when its address is taken.
+func findAssignedVars(info *types.Info, files []*ast.File) map[types.Object][]ast.Expr {
+	assigned := make(map[types.Object][]ast.Expr)
+	add := func(expr ast.Expr, value ast.Expr) {
+		if ident, ok := expr.(*ast.Ident); ok {
+			// the variables declared by the statement are in the definitions
+			if obj, ok := info.Uses[ident].(*types.Var); ok {
+				assigned[obj] = append(assigned[obj], value)
+			}
+		}
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range node.Lhs {
+					if len(node.Lhs) == len(node.Rhs) {
+						add(lhs, node.Rhs[i])
+					} else {
+						add(lhs, nil)
+					}
+				}
+			case *ast.IncDecStmt:
+				add(node.X, nil)
+			case *ast.RangeStmt:
+				if node.Tok == token.ASSIGN {
+					add(node.Key, nil)
+					add(node.Value, nil)
+				}
+			case *ast.UnaryExpr:
+				if node.Op == token.AND {
+					add(node.X, nil)
+				}
+			}
+			return true
+		})
+	}
+	return assigned
+}
 
 func resolveIdent(n *ast.Ident, c *Context) bool {
 	if n.Obj == nil || n.Obj.Kind != ast.Var {
 		return true
 	}
+	if c.Info != nil && c.Pkg != nil {
+		if obj := c.Info.ObjectOf(n); obj != nil {
+			// a variable assigned from itself is constant when its other values are
+			if c.resolving[obj] {
+				return true
+			}
+			if values, ok := c.resolved.assignedVars(c)[obj]; ok {
+				return resolveAssignedVar(n, obj, values, c)
+			}
+		}
+	}
 	if node, ok := n.Obj.Decl.(ast.Node); ok {
 		return TryResolve(node, c)
 	}
 	return false
 }
 
+// resolveAssignedVar checks that the declaration and every assignment of a
+// variable yield constant values
+func resolveAssignedVar(n *ast.Ident, obj types.Object, values []ast.Expr, c *Context) bool {
+	decl, ok := n.Obj.Decl.(ast.Node)
+	if !ok {
+		return false
+	}
+	if c.resolving == nil {
+		c.resolving = make(map[types.Object]bool)
+	}
+	c.resolving[obj] = true
+	defer delete(c.resolving, obj)
+	if !TryResolve(decl, c) {
+		return false
+	}
+	for _, value := range values {
+		if value == nil || !TryResolve(value, c) {
+			return false
+		}
+	}
+	return true
+}
+
 func resolveValueSpec(n *ast.ValueSpec, c *Context) bool {
 	if len(n.Values) == 0 {
 		return false
@@ -67,8 +244,97 @@ func resolveBinExpr(n *ast.BinaryExpr, c *Context) bool {
 }
 
 func resolveCallExpr(n *ast.CallExpr, c *Context) bool {
-	// TODO(tkelsey): next step, full function resolution
-	return false
+	if c.Info == nil {
+		return false
+	}
+	fn := typeutil.StaticCallee(c.Info, n)
+	if fn == nil || !fn.Pos().IsValid() {
+		return false
+	}
+	if resolved, ok := c.resolved.get(fn); ok {
+		return resolved
+	}
+	// A recursive call cannot be resolved before the function itself
+	if c.resolving[fn] {
+		return false
+	}
+	if c.resolving == nil {
+		c.resolving = make(map[types.Object]bool)
+	}
+	callee := c
+	if fn.Pkg() != c.Pkg {
+		// the function is resolved within the context of its own package, and
+		// is not resolved when its package is not part of the load
+		pkg := c.resolved.lookupPackage(fn.Pkg())
+		if pkg == nil {
+			c.resolved.set(fn, false)
+			return false
+		}
+		pkgContext := *c
+		pkgContext.Info = pkg.TypesInfo
+		pkgContext.Pkg = pkg.Types
+		pkgContext.PkgFiles = pkg.Syntax
+		callee = &pkgContext
+	}
+	decl := findFuncDecl(fn, callee.PkgFiles)
+	if decl == nil {
+		c.resolved.set(fn, false)
+		return false
+	}
+	c.resolving[fn] = true
+	resolved := resolveFuncDecl(decl, callee)
+	delete(c.resolving, fn)
+	c.resolved.set(fn, resolved)
+	return resolved
+}
+
+// findFuncDecl looks up the declaration of a function in the package files
+func findFuncDecl(fn *types.Func, files []*ast.File) *ast.FuncDecl {
+	for _, file := range files {
+		if fn.Pos() < file.Pos() || fn.Pos() >= file.End() {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Pos() == fn.Pos() {
+				return funcDecl
+			}
+		}
+	}
+	return nil
+}
+
+// resolveFuncDecl checks that every return path of a function yields constant values
+func resolveFuncDecl(decl *ast.FuncDecl, c *Context) bool {
+	if decl.Body == nil {
+		return false
+	}
+	returns := 0
+	resolved := true
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if !resolved {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			// the returns of the closures don't belong to the function
+			return false
+		case *ast.ReturnStmt:
+			returns++
+			// a naked return yields the named results, which are not tracked
+			if len(node.Results) == 0 {
+				resolved = false
+				return false
+			}
+			for _, result := range node.Results {
+				if !TryResolve(result, c) {
+					resolved = false
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return resolved && returns > 0
 }
 
 // TryResolve will attempt, given a subtree starting at some AST node, to resolve
@@ -318,6 +318,66 @@ var _ = Describe("Resolve ast node to concrete value", func() {
 			Expect(gosec.TryResolve(value, ctx)).Should(BeFalse())
 		})
 
+		It("should successfully resolve call expressions to functions returning constants", func() {
+			var value *ast.CallExpr
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("foo.go", `package main; func main(){ println(bar()) }`)
+			pkg.AddFile("bar.go", `package main; const prefix = "/bin/"; func bar() string { if prefix == "" { return "sh" }; return prefix + "sh" }`)
+			ctx := pkg.CreateContext("foo.go")
+			v := testutils.NewMockVisitor()
+			v.Callback = func(n ast.Node, ctx *gosec.Context) bool {
+				if node, ok := n.(*ast.CallExpr); ok {
+					value = node
+				}
+				return true
+			}
+			v.Context = ctx
+			ast.Walk(v, ctx.Root)
+			Expect(value).ShouldNot(BeNil())
+			Expect(gosec.TryResolve(value, ctx)).Should(BeTrue())
+		})
+
+		It("should successfully not resolve call expressions to functions returning variables", func() {
+			var value *ast.CallExpr
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("foo.go", `package main; import "os"; func bar() string { if len(os.Args) > 1 { return os.Args[1] }; return "sh" }; func main(){ println(bar()) }`)
+			ctx := pkg.CreateContext("foo.go")
+			v := testutils.NewMockVisitor()
+			v.Callback = func(n ast.Node, ctx *gosec.Context) bool {
+				if node, ok := n.(*ast.CallExpr); ok {
+					value = node
+				}
+				return true
+			}
+			v.Context = ctx
+			ast.Walk(v, ctx.Root)
+			Expect(value).ShouldNot(BeNil())
+			Expect(gosec.TryResolve(value, ctx)).Should(BeFalse())
+		})
+
+		It("should successfully not resolve reassigned variable identifier", func() {
+			var ident *ast.Ident
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("foo.go", `package main; import "os"; func main(){ y := "bar"; y = os.Args[0]; println(y) }`)
+			ctx := pkg.CreateContext("foo.go")
+			v := testutils.NewMockVisitor()
+			v.Callback = func(n ast.Node, ctx *gosec.Context) bool {
+				if node, ok := n.(*ast.CallExpr); ok && len(node.Args) == 1 {
+					if arg, ok := node.Args[0].(*ast.Ident); ok && arg.Name == "y" {
+						ident = arg
+					}
+				}
+				return true
+			}
+			v.Context = ctx
+			ast.Walk(v, ctx.Root)
+			Expect(ident).ShouldNot(BeNil())
+			Expect(gosec.TryResolve(ident, ctx)).Should(BeFalse())
+		})
+
 		It("should successfully not resolve call expressions", func() {
 			var value *ast.ImportSpec
 			pkg := testutils.NewTestPackage()
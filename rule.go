@@ -23,6 +23,33 @@ type Rule interface {
 	Match(ast.Node, *Context) (*Issue, error)
 }
 
+// MultiIssueRule is implemented by the rules which may report several issues for
+// the same AST node, e.g. each insecure setting of a configuration literal. The
+// analyzer calls MatchAll instead of Match for these rules.
+type MultiIssueRule interface {
+	Rule
+	MatchAll(ast.Node, *Context) ([]*Issue, error)
+}
+
+// DescribedRule is implemented by the rules embedding MetaData, whose severity
+// and confidence can then be known without matching any node
+type DescribedRule interface {
+	Rule
+	Describe() MetaData
+}
+
+// matchAll returns all the issues found by the rule on the node
+func matchAll(rule Rule, n ast.Node, c *Context) ([]*Issue, error) {
+	if multi, ok := rule.(MultiIssueRule); ok {
+		return multi.MatchAll(n, c)
+	}
+	issue, err := rule.Match(n, c)
+	if issue == nil {
+		return nil, err
+	}
+	return []*Issue{issue}, err
+}
+
 // RuleBuilder is used to register a rule definition with the analyzer
 type RuleBuilder func(id string, c Config) (Rule, []ast.Node)
 
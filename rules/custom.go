@@ -0,0 +1,423 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"gopkg.in/yaml.v2"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/pattern"
+)
+
+// ruleID is the format of the rule IDs, which can then be listed in the #nosec
+// directives
+var ruleID = regexp.MustCompile(`^[A-Z]+\d{3,}$`)
+
+// RulesFile holds the rules declared in a YAML or JSON file
+type RulesFile struct {
+	Rules []CustomRule `json:"rules" yaml:"rules"`
+}
+
+// CustomRule declares a rule flagging either the calls matched by any of its
+// matchers, or the code matched by its pattern
+type CustomRule struct {
+	ID          string        `json:"id" yaml:"id"`
+	Description string        `json:"description" yaml:"description"`
+	Severity    string        `json:"severity" yaml:"severity"`     // low, medium (default) or high
+	Confidence  string        `json:"confidence" yaml:"confidence"` // low, medium (default) or high
+	CWE         string        `json:"cwe" yaml:"cwe"`
+	Message     string        `json:"message" yaml:"message"`
+	Calls       []CallMatcher `json:"calls" yaml:"calls"`
+
+	// Pattern is a code pattern, see the pattern package
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// PatternNot are the patterns of the code which is not flagged
+	PatternNot []string `json:"pattern-not" yaml:"pattern-not"`
+	// PatternInside is the pattern of the code enclosing the flagged code
+	PatternInside string `json:"pattern-inside" yaml:"pattern-inside"`
+	// Metavariables are the constraints on the metavariables of the patterns, keyed by name
+	Metavariables map[string]MetavariableConstraint `json:"metavariables" yaml:"metavariables"`
+}
+
+// MetavariableConstraint constrains the nodes bound to a metavariable
+type MetavariableConstraint struct {
+	Type string `json:"type" yaml:"type"` // type of the expressions, e.g. "*database/sql.DB"
+}
+
+// CallMatcher matches the calls to a function of a package, or to a method of a
+// type of a package. The call is flagged when all the argument constraints hold.
+type CallMatcher struct {
+	Package string       `json:"package" yaml:"package"` // import path, e.g. "database/sql"
+	Type    string       `json:"type" yaml:"type"`       // receiver type of a method, e.g. "DB"
+	Method  string       `json:"method" yaml:"method"`   // name of the function or of the method
+	Args    []ArgMatcher `json:"args" yaml:"args"`
+}
+
+// ArgMatcher constrains an argument of a call. The constraints of an argument
+// must all hold.
+type ArgMatcher struct {
+	Index       int    `json:"index" yaml:"index"`               // index of the argument, starting at 0
+	NonConstant bool   `json:"non_constant" yaml:"non_constant"` // the argument can't be resolved to a constant
+	Matches     string `json:"matches" yaml:"matches"`           // the constant string argument matches the regexp
+	Above       *int64 `json:"above" yaml:"above"`               // the constant integer argument is above the threshold
+}
+
+// LoadRulesFile reads the rules declared in a YAML or JSON file, and compiles
+// them into rule definitions. The files with a ".json" extension are decoded as
+// JSON, the other ones as YAML.
+func LoadRulesFile(path string) ([]RuleDefinition, error) {
+	data, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	var file RulesFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	} else {
+		err = yaml.UnmarshalStrict(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file %q: %v", path, err)
+	}
+
+	definitions := make([]RuleDefinition, 0, len(file.Rules))
+	for i := range file.Rules {
+		def, err := file.Rules[i].compile()
+		if err != nil {
+			return nil, fmt.Errorf("rules file %q: %v", path, err)
+		}
+		definitions = append(definitions, def)
+	}
+	return definitions, nil
+}
+
+// RegisterRulesFile registers the rules declared in a YAML or JSON file, see
+// LoadRulesFile. The file is rejected when a rule ID is already used.
+func RegisterRulesFile(path string) error {
+	definitions, err := LoadRulesFile(path)
+	if err != nil {
+		return err
+	}
+	known := Generate()
+	for _, def := range definitions {
+		if _, ok := known[def.ID]; ok {
+			return fmt.Errorf("rules file %q: rule %s is already defined", path, def.ID)
+		}
+		known[def.ID] = def
+	}
+	for _, def := range definitions {
+		Register(def)
+	}
+	return nil
+}
+
+// compile checks the declaration of the rule, and returns its definition
+func (r *CustomRule) compile() (RuleDefinition, error) {
+	switch {
+	case r.ID == "":
+		return RuleDefinition{}, fmt.Errorf("rule without ID")
+	case !ruleID.MatchString(r.ID):
+		return RuleDefinition{}, fmt.Errorf("invalid rule ID %q, expected upper case letters followed by at least 3 digits", r.ID)
+	case r.Message == "":
+		return RuleDefinition{}, fmt.Errorf("rule %s without message", r.ID)
+	case r.CWE == "":
+		return RuleDefinition{}, fmt.Errorf("rule %s without CWE", r.ID)
+	case len(r.Calls) == 0 && r.Pattern == "":
+		return RuleDefinition{}, fmt.Errorf("rule %s without calls or pattern", r.ID)
+	case len(r.Calls) > 0 && r.Pattern != "":
+		return RuleDefinition{}, fmt.Errorf("rule %s with both calls and pattern", r.ID)
+	case r.Pattern == "" && (len(r.PatternNot) > 0 || r.PatternInside != "" || len(r.Metavariables) > 0):
+		return RuleDefinition{}, fmt.Errorf("rule %s without pattern", r.ID)
+	}
+	severity, err := parseScore(r.Severity)
+	if err != nil {
+		return RuleDefinition{}, fmt.Errorf("rule %s: invalid severity: %v", r.ID, err)
+	}
+	confidence, err := parseScore(r.Confidence)
+	if err != nil {
+		return RuleDefinition{}, fmt.Errorf("rule %s: invalid confidence: %v", r.ID, err)
+	}
+
+	description := r.Description
+	if description == "" {
+		description = r.Message
+	}
+	metaData := func(id string) gosec.MetaData {
+		return gosec.MetaData{
+			ID:         id,
+			What:       r.Message,
+			Severity:   severity,
+			Confidence: confidence,
+		}
+	}
+	def := RuleDefinition{
+		ID:          r.ID,
+		Description: description,
+		Severity:    severity,
+		Confidence:  confidence,
+		CWE:         r.CWE,
+		Tags:        []string{"custom"},
+	}
+
+	if r.Pattern != "" {
+		rule, err := r.compilePatterns()
+		if err != nil {
+			return RuleDefinition{}, fmt.Errorf("rule %s: %v", r.ID, err)
+		}
+		def.Create = func(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+			return &patternRule{
+				MetaData: metaData(id),
+				pattern:  rule.pattern,
+				not:      rule.not,
+				inside:   rule.inside,
+			}, []ast.Node{rule.pattern.Node()}
+		}
+		return def, nil
+	}
+
+	calls := make([]*callMatcher, 0, len(r.Calls))
+	for _, call := range r.Calls {
+		matcher, err := call.compile()
+		if err != nil {
+			return RuleDefinition{}, fmt.Errorf("rule %s: %v", r.ID, err)
+		}
+		calls = append(calls, matcher)
+	}
+	def.Create = func(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+		return &customRule{
+			MetaData: metaData(id),
+			calls:    calls,
+		}, []ast.Node{(*ast.CallExpr)(nil)}
+	}
+	return def, nil
+}
+
+// compilePatterns compiles the patterns of the rule along with the constraints on
+// their metavariables
+func (r *CustomRule) compilePatterns() (*patternRule, error) {
+	rule := &patternRule{}
+	var err error
+	if rule.pattern, err = pattern.Compile(r.Pattern); err != nil {
+		return nil, err
+	}
+	patterns := []*pattern.Pattern{rule.pattern}
+	for _, src := range r.PatternNot {
+		not, err := pattern.Compile(src)
+		if err != nil {
+			return nil, err
+		}
+		rule.not = append(rule.not, not)
+		patterns = append(patterns, not)
+	}
+	if r.PatternInside != "" {
+		if rule.inside, err = pattern.Compile(r.PatternInside); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, rule.inside)
+	}
+
+	for name, constraint := range r.Metavariables {
+		if constraint.Type == "" {
+			return nil, fmt.Errorf("metavariable %s without constraint", name)
+		}
+		found := false
+		for _, p := range patterns {
+			if p.HasMetavariable(name) {
+				p.Where(name, pattern.TypeIs(constraint.Type))
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("metavariable %s not found in the patterns", name)
+		}
+	}
+	return rule, nil
+}
+
+// parseScore parses a severity or a confidence, which is medium when empty
+func parseScore(value string) (gosec.Score, error) {
+	if value == "" {
+		return gosec.Medium, nil
+	}
+	for _, score := range []gosec.Score{gosec.Low, gosec.Medium, gosec.High} {
+		if strings.EqualFold(value, score.String()) {
+			return score, nil
+		}
+	}
+	return gosec.Low, fmt.Errorf("%q, expected low, medium or high", value)
+}
+
+// callMatcher is a compiled CallMatcher
+type callMatcher struct {
+	CallMatcher
+	args []*argMatcher
+}
+
+// argMatcher is a compiled ArgMatcher
+type argMatcher struct {
+	ArgMatcher
+	matches *regexp.Regexp
+}
+
+func (m CallMatcher) compile() (*callMatcher, error) {
+	if m.Package == "" || m.Method == "" {
+		return nil, fmt.Errorf("call matcher without package or method")
+	}
+	matcher := &callMatcher{CallMatcher: m}
+	for _, arg := range m.Args {
+		if arg.Index < 0 {
+			return nil, fmt.Errorf("invalid argument index %d of %s", arg.Index, m.Method)
+		}
+		if !arg.NonConstant && arg.Matches == "" && arg.Above == nil {
+			return nil, fmt.Errorf("argument %d of %s without constraint", arg.Index, m.Method)
+		}
+		compiled := &argMatcher{ArgMatcher: arg}
+		if arg.Matches != "" {
+			re, err := regexp.Compile(arg.Matches)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp of the argument %d of %s: %v", arg.Index, m.Method, err)
+			}
+			compiled.matches = re
+		}
+		matcher.args = append(matcher.args, compiled)
+	}
+	return matcher, nil
+}
+
+// customRule flags the calls matched by any of its matchers
+type customRule struct {
+	gosec.MetaData
+	calls []*callMatcher
+}
+
+func (r *customRule) ID() string {
+	return r.MetaData.ID
+}
+
+func (r *customRule) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	call, obj := gosec.GetCallObject(n, c)
+	fn, ok := obj.(*types.Func)
+	if call == nil || !ok || fn.Pkg() == nil {
+		return nil, nil
+	}
+	for _, matcher := range r.calls {
+		if matcher.matchFunc(fn) && matcher.matchArgs(call, c) {
+			return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+		}
+	}
+	return nil, nil
+}
+
+// patternRule flags the code matched by its pattern, which matches none of its
+// pattern-not patterns and is enclosed in code matching its pattern-inside pattern
+type patternRule struct {
+	gosec.MetaData
+	pattern *pattern.Pattern
+	not     []*pattern.Pattern
+	inside  *pattern.Pattern
+}
+
+func (r *patternRule) ID() string {
+	return r.MetaData.ID
+}
+
+func (r *patternRule) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	bindings, ok := r.pattern.Match(n, c.Info, nil)
+	if !ok {
+		return nil, nil
+	}
+	for _, not := range r.not {
+		if _, ok := not.Match(n, c.Info, bindings); ok {
+			return nil, nil
+		}
+	}
+	if r.inside != nil && !r.enclosed(n, c, bindings) {
+		return nil, nil
+	}
+	return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+}
+
+// enclosed checks whether the node is enclosed in a node matching the pattern-inside pattern
+func (r *patternRule) enclosed(n ast.Node, c *gosec.Context, bindings pattern.Bindings) bool {
+	if c.Root == nil {
+		return false
+	}
+	path, _ := astutil.PathEnclosingInterval(c.Root, n.Pos(), n.End())
+	for _, enclosing := range path {
+		if _, ok := r.inside.Match(enclosing, c.Info, bindings); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFunc checks the package, the receiver type and the name of the function
+func (m *callMatcher) matchFunc(fn *types.Func) bool {
+	if fn.Name() != m.Method || gosec.StripVendor(fn.Pkg().Path()) != m.Package {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return m.Type == ""
+	}
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := recvType.(*types.Named)
+	return ok && named.Obj().Name() == m.Type
+}
+
+func (m *callMatcher) matchArgs(call *ast.CallExpr, c *gosec.Context) bool {
+	for _, arg := range m.args {
+		if arg.Index >= len(call.Args) || !arg.match(call.Args[arg.Index], c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *argMatcher) match(arg ast.Expr, c *gosec.Context) bool {
+	if m.NonConstant && gosec.TryResolve(arg, c) {
+		return false
+	}
+	value := c.Info.Types[arg].Value
+	if m.matches != nil {
+		if value == nil || value.Kind() != constant.String || !m.matches.MatchString(constant.StringVal(value)) {
+			return false
+		}
+	}
+	if m.Above != nil {
+		if value == nil || value.Kind() != constant.Int {
+			return false
+		}
+		if v, exact := constant.Int64Val(value); !exact || v <= *m.Above {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package rules_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Custom rules", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gosec-rules")
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).Should(Succeed())
+		return path
+	}
+
+	It("should report the calls matching the declared rules", func() {
+		path := writeFile("rules.yaml", `
+rules:
+  - id: C101
+    severity: high
+    confidence: medium
+    cwe: "89"
+    message: SQL query built from a non constant string
+    calls:
+      - package: database/sql
+        type: DB
+        method: Query
+        args:
+          - index: 0
+            non_constant: true
+  - id: C102
+    cwe: "400"
+    message: Sleep longer than a minute
+    calls:
+      - package: time
+        method: Sleep
+        args:
+          - index: 0
+            above: 60000000000
+  - id: C103
+    severity: low
+    cwe: "798"
+    message: Connection to the production host
+    calls:
+      - package: net
+        method: Dial
+        args:
+          - index: 1
+            matches: "^prod\\."
+`)
+		definitions, err := rules.LoadRulesFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(definitions).To(HaveLen(3))
+		Expect(definitions[0].Severity).To(Equal(gosec.High))
+		Expect(definitions[1].Severity).To(Equal(gosec.Medium))
+		Expect(definitions[2].Tags).To(Equal([]string{"custom"}))
+
+		pkg := testutils.NewTestPackage()
+		defer pkg.Close()
+		pkg.AddFile("main.go", `
+			package main
+			import (
+				"database/sql"
+				"net"
+				"os"
+				"time"
+			)
+			func main() {
+				db, _ := sql.Open("sqlite3", ":memory:")
+				db.Query("SELECT 1")
+				db.Query(os.Args[1])
+				time.Sleep(time.Second)
+				time.Sleep(2 * time.Minute)
+				net.Dial("tcp", "dev.example.com:80")
+				net.Dial("tcp", "prod.example.com:80")
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+
+		ruleList := rules.RuleList{}
+		for _, def := range definitions {
+			ruleList[def.ID] = def
+		}
+		logger, _ := testutils.NewLogger()
+		analyzer := gosec.NewAnalyzer(nil, false, logger)
+		analyzer.LoadRules(ruleList.Builders())
+		Expect(analyzer.Process(nil, pkg.Path)).Should(Succeed())
+		issues, _, _ := analyzer.Report()
+		Expect(issues).To(HaveLen(3))
+		lines := map[string]string{}
+		for _, issue := range issues {
+			lines[issue.RuleID] = issue.Line
+		}
+		Expect(lines).To(Equal(map[string]string{"C101": "12", "C102": "14", "C103": "16"}))
+	})
+
+	It("should report the code matching the declared patterns", func() {
+		path := writeFile("patterns.yaml", `
+rules:
+  - id: C131
+    cwe: "89"
+    message: SQL query formatted with arguments
+    pattern: $DB.Exec(fmt.Sprintf($FMT, $...ARGS))
+    pattern-not:
+      - $DB.Exec(fmt.Sprintf($FMT, "constant"))
+    metavariables:
+      $DB:
+        type: "*database/sql.DB"
+  - id: C132
+    cwe: "614"
+    message: Cookie without the Secure attribute
+    pattern: 'http.Cookie{..., Secure: false, ...}'
+    pattern-inside: |
+      func $H($W http.ResponseWriter, $R *http.Request) {
+        ...
+      }
+`)
+		definitions, err := rules.LoadRulesFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pkg := testutils.NewTestPackage()
+		defer pkg.Close()
+		pkg.AddFile("main.go", `
+			package main
+			import (
+				"database/sql"
+				"fmt"
+				"net/http"
+				"os"
+			)
+			type store struct{}
+			func (store) Exec(query string) {}
+			func handler(w http.ResponseWriter, r *http.Request) {
+				_ = http.Cookie{Name: "session", Secure: false}
+			}
+			func main() {
+				_ = http.Cookie{Name: "session", Secure: false}
+				db, _ := sql.Open("sqlite3", ":memory:")
+				db.Exec(fmt.Sprintf("DELETE FROM %s", os.Args[1]))
+				db.Exec(fmt.Sprintf("DELETE FROM %s", "constant"))
+				store{}.Exec(fmt.Sprintf("DELETE FROM %s", os.Args[1]))
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+
+		ruleList := rules.RuleList{}
+		for _, def := range definitions {
+			ruleList[def.ID] = def
+		}
+		logger, _ := testutils.NewLogger()
+		analyzer := gosec.NewAnalyzer(nil, false, logger)
+		analyzer.LoadRules(ruleList.Builders())
+		Expect(analyzer.Process(nil, pkg.Path)).Should(Succeed())
+		issues, _, _ := analyzer.Report()
+		lines := map[string]string{}
+		for _, issue := range issues {
+			lines[issue.RuleID] = issue.Line
+		}
+		Expect(issues).To(HaveLen(2))
+		Expect(lines).To(Equal(map[string]string{"C131": "17", "C132": "12"}))
+	})
+
+	It("should register the rules of a JSON file", func() {
+		path := writeFile("rules.json", `{"rules": [{
+			"id": "C111",
+			"cwe": "78",
+			"message": "Command started from a non constant name",
+			"calls": [{"package": "os/exec", "method": "Command", "args": [{"index": 0, "non_constant": true}]}]
+		}]}`)
+		Expect(rules.RegisterRulesFile(path)).Should(Succeed())
+		Expect(rules.Generate()).To(HaveKey("C111"))
+		Expect(rules.RegisterRulesFile(path)).To(MatchError(ContainSubstring("rule C111 is already defined")))
+	})
+
+	It("should reject the invalid rules", func() {
+		for content, message := range map[string]string{
+			"rules:\n  - id: C121\n    cwe: \"1\"\n    calls: [{package: os, method: Exit}]\n":                                                     "rule C121 without message",
+			"rules:\n  - id: C122\n    message: m\n    calls: [{package: os, method: Exit}]\n":                                                     "rule C122 without CWE",
+			"rules:\n  - id: C123\n    cwe: \"1\"\n    message: m\n    severity: critical\n    calls: [{package: os, method: Exit}]\n":             "rule C123: invalid severity",
+			"rules:\n  - id: C124\n    cwe: \"1\"\n    message: m\n    calls: [{method: Exit}]\n":                                                  "rule C124: call matcher without package or method",
+			"rules:\n  - id: C125\n    cwe: \"1\"\n    message: m\n    calls: [{package: os, method: Exit, args: [{index: 0, matches: \"(\"}]}]\n": "rule C125: invalid regexp",
+			"rules:\n  - id: C127\n    cwe: \"1\"\n    message: m\n    pattern: $X\n":                                                              "rule C127: invalid pattern",
+			"rules:\n  - id: C128\n    cwe: \"1\"\n    message: m\n    pattern: $X.Close()\n    metavariables: {$Y: {type: int}}\n":                "rule C128: metavariable $Y not found",
+			"rules:\n  - id: C126\n    cwe: \"1\"\n    message: m\n    sinks: []\n":                                                                "field sinks not found",
+			"rules:\n  - id: custom-1\n    cwe: \"1\"\n    message: m\n    calls: [{package: os, method: Exit}]\n":                                 "invalid rule ID \"custom-1\"",
+			"rules:\n  - id: C129\n    cwe: \"1\"\n    message: m\n    calls: [{package: os, method: Exit, args: [{index: 0}]}]\n":                 "rule C129: argument 0 of Exit without constraint",
+		} {
+			_, err := rules.LoadRulesFile(writeFile("invalid.yaml", content))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		}
+	})
+})
@@ -54,6 +54,15 @@ func (r *credentials) isHighEntropyString(str string) bool {
 }
 
 func (r *credentials) Match(n ast.Node, ctx *gosec.Context) (*gosec.Issue, error) {
+	issues, err := r.MatchAll(n, ctx)
+	if len(issues) == 0 {
+		return nil, err
+	}
+	return issues[0], err
+}
+
+// MatchAll reports each credential assigned or declared by the node
+func (r *credentials) MatchAll(n ast.Node, ctx *gosec.Context) ([]*gosec.Issue, error) {
 	switch node := n.(type) {
 	case *ast.AssignStmt:
 		return r.matchAssign(node, ctx)
@@ -65,24 +74,32 @@ func (r *credentials) Match(n ast.Node, ctx *gosec.Context) (*gosec.Issue, error
 	return nil, nil
 }
 
-func (r *credentials) matchAssign(assign *ast.AssignStmt, ctx *gosec.Context) (*gosec.Issue, error) {
-	for _, i := range assign.Lhs {
+func (r *credentials) matchAssign(assign *ast.AssignStmt, ctx *gosec.Context) ([]*gosec.Issue, error) {
+	var issues []*gosec.Issue
+	for index, i := range assign.Lhs {
 		if ident, ok := i.(*ast.Ident); ok {
 			if r.pattern.MatchString(ident.Name) {
-				for _, e := range assign.Rhs {
+				values := assign.Rhs
+				// a, b := "x", "y" assigns each value to its own variable
+				if len(assign.Lhs) == len(assign.Rhs) {
+					values = assign.Rhs[index : index+1]
+				}
+				for _, e := range values {
 					if val, err := gosec.GetString(e); err == nil {
 						if r.ignoreEntropy || (!r.ignoreEntropy && r.isHighEntropyString(val)) {
-							return gosec.NewIssue(ctx, assign, r.ID(), r.What, r.Severity, r.Confidence), nil
+							issues = append(issues, r.newIssue(ctx, assign, ident, len(assign.Lhs)))
+							break
 						}
 					}
 				}
 			}
 		}
 	}
-	return nil, nil
+	return issues, nil
 }
 
-func (r *credentials) matchValueSpec(valueSpec *ast.ValueSpec, ctx *gosec.Context) (*gosec.Issue, error) {
+func (r *credentials) matchValueSpec(valueSpec *ast.ValueSpec, ctx *gosec.Context) ([]*gosec.Issue, error) {
+	var issues []*gosec.Issue
 	for index, ident := range valueSpec.Names {
 		if r.pattern.MatchString(ident.Name) && valueSpec.Values != nil {
 			// const foo, bar = "same value"
@@ -91,21 +108,21 @@ func (r *credentials) matchValueSpec(valueSpec *ast.ValueSpec, ctx *gosec.Contex
 			}
 			if val, err := gosec.GetString(valueSpec.Values[index]); err == nil {
 				if r.ignoreEntropy || (!r.ignoreEntropy && r.isHighEntropyString(val)) {
-					return gosec.NewIssue(ctx, valueSpec, r.ID(), r.What, r.Severity, r.Confidence), nil
+					issues = append(issues, r.newIssue(ctx, valueSpec, ident, len(valueSpec.Names)))
 				}
 			}
 		}
 	}
-	return nil, nil
+	return issues, nil
 }
 
-func (r *credentials) matchEqualityCheck(binaryExpr *ast.BinaryExpr, ctx *gosec.Context) (*gosec.Issue, error) {
+func (r *credentials) matchEqualityCheck(binaryExpr *ast.BinaryExpr, ctx *gosec.Context) ([]*gosec.Issue, error) {
 	if binaryExpr.Op == token.EQL || binaryExpr.Op == token.NEQ {
 		if ident, ok := binaryExpr.X.(*ast.Ident); ok {
 			if r.pattern.MatchString(ident.Name) {
 				if val, err := gosec.GetString(binaryExpr.Y); err == nil {
 					if r.ignoreEntropy || (!r.ignoreEntropy && r.isHighEntropyString(val)) {
-						return gosec.NewIssue(ctx, binaryExpr, r.ID(), r.What, r.Severity, r.Confidence), nil
+						return []*gosec.Issue{gosec.NewIssue(ctx, binaryExpr, r.ID(), r.What, r.Severity, r.Confidence)}, nil
 					}
 				}
 			}
@@ -114,6 +131,15 @@ func (r *credentials) matchEqualityCheck(binaryExpr *ast.BinaryExpr, ctx *gosec.
 	return nil, nil
 }
 
+// newIssue reports a credential. The issue points to the identifier when the node
+// declares several names, so that the issues of the same node can be told apart.
+func (r *credentials) newIssue(ctx *gosec.Context, node ast.Node, ident *ast.Ident, names int) *gosec.Issue {
+	if names > 1 {
+		node = ident
+	}
+	return gosec.NewIssue(ctx, node, r.ID(), r.What, r.Severity, r.Confidence)
+}
+
 // NewHardcodedCredentials attempts to find high entropy string constants being
 // assigned to variables that appear to be related to credentials.
 func NewHardcodedCredentials(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
@@ -123,7 +149,8 @@ func NewHardcodedCredentials(id string, conf gosec.Config) (gosec.Rule, []ast.No
 	ignoreEntropy := false
 	truncateString := 16
 	if val, ok := conf["G101"]; ok {
-		conf := val.(map[string]interface{})
+		// an invalid section is ignored like the invalid options below
+		conf, _ := val.(map[string]interface{})
 		if configPattern, ok := conf["pattern"]; ok {
 			if cfgPattern, ok := configPattern.(string); ok {
 				pattern = cfgPattern
@@ -74,6 +74,14 @@ func (r *readfile) isFilepathClean(n *ast.Ident, c *gosec.Context) bool {
 
 // Match inspects AST nodes to determine if the match the methods `os.Open` or `ioutil.ReadFile`
 func (r *readfile) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	if call, ok := n.(*ast.CallExpr); ok {
+		if tainted, ok := gosec.IsTaintedSink(r.ID(), call, c); ok {
+			if tainted {
+				return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+			}
+			return nil, nil
+		}
+	}
 	if node := r.ContainsPkgCallExpr(n, c, false); node != nil {
 		for _, arg := range node.Args {
 			// handles path joining functions in Arg
@@ -14,7 +14,12 @@
 
 package rules
 
-import "github.com/securego/gosec/v2"
+import (
+	"fmt"
+	"sync"
+
+	"github.com/securego/gosec/v2"
+)
 
 // RuleDefinition contains the description of a rule and a mechanism to
 // create it.
@@ -22,6 +27,14 @@ type RuleDefinition struct {
 	ID          string
 	Description string
 	Create      gosec.RuleBuilder
+	// Severity and Confidence are the default severity and confidence of the issues
+	// of the rule. They are taken from the metadata of the rules provided by gosec.
+	Severity   gosec.Score
+	Confidence gosec.Score
+	// CWE is the ID of the weakness found by the rule, e.g. "79"
+	CWE string
+	// Tags classify the rule, e.g. "crypto"
+	Tags []string
 }
 
 // RuleList is a mapping of rule ID's to rule definitions
@@ -55,52 +68,109 @@ func NewRuleFilter(action bool, ruleIDs ...string) RuleFilter {
 	}
 }
 
-// Generate the list of rules to use
-func Generate(filters ...RuleFilter) RuleList {
-	rules := []RuleDefinition{
-		// misc
-		{"G101", "Look for hardcoded credentials", NewHardcodedCredentials},
-		{"G102", "Bind to all interfaces", NewBindsToAllNetworkInterfaces},
-		{"G103", "Audit the use of unsafe block", NewUsingUnsafe},
-		{"G104", "Audit errors not checked", NewNoErrorCheck},
-		{"G106", "Audit the use of ssh.InsecureIgnoreHostKey function", NewSSHHostKey},
-		{"G107", "Url provided to HTTP request as taint input", NewSSRFCheck},
-		{"G108", "Profiling endpoint is automatically exposed", NewPprofCheck},
-		{"G109", "Converting strconv.Atoi result to int32/int16", NewIntegerOverflowCheck},
-		{"G110", "Detect io.Copy instead of io.CopyN when decompression", NewDecompressionBombCheck},
-
-		// injection
-		{"G201", "SQL query construction using format string", NewSQLStrFormat},
-		{"G202", "SQL query construction using string concatenation", NewSQLStrConcat},
-		{"G203", "Use of unescaped data in HTML templates", NewTemplateCheck},
-		{"G204", "Audit use of command execution", NewSubproc},
-
-		// filesystem
-		{"G301", "Poor file permissions used when creating a directory", NewMkdirPerms},
-		{"G302", "Poor file permissions used when creation file or using chmod", NewFilePerms},
-		{"G303", "Creating tempfile using a predictable path", NewBadTempFile},
-		{"G304", "File path provided as taint input", NewReadFile},
-		{"G305", "File path traversal when extracting zip archive", NewArchive},
-		{"G306", "Poor file permissions used when writing to a file", NewWritePerms},
-		{"G307", "Unsafe defer call of a method returning an error", NewDeferredClosing},
-
-		// crypto
-		{"G401", "Detect the usage of DES, RC4, MD5 or SHA1", NewUsesWeakCryptography},
-		{"G402", "Look for bad TLS connection settings", NewIntermediateTLSCheck},
-		{"G403", "Ensure minimum RSA key length of 2048 bits", NewWeakKeyStrength},
-		{"G404", "Insecure random number source (rand)", NewWeakRandCheck},
-
-		// blocklist
-		{"G501", "Import blocklist: crypto/md5", NewBlocklistedImportMD5},
-		{"G502", "Import blocklist: crypto/des", NewBlocklistedImportDES},
-		{"G503", "Import blocklist: crypto/rc4", NewBlocklistedImportRC4},
-		{"G504", "Import blocklist: net/http/cgi", NewBlocklistedImportCGI},
-		{"G505", "Import blocklist: crypto/sha1", NewBlocklistedImportSHA1},
-
-		// memory safety
-		{"G601", "Implicit memory aliasing in RangeStmt", NewImplicitAliasing},
+// builtin holds the definitions of the rules provided by gosec. Their CWE is
+// given by gosec.GetCweByRule, and their severity and confidence by the rules
+// created by their builder.
+var builtin = []RuleDefinition{
+	// misc
+	{ID: "G101", Description: "Look for hardcoded credentials", Create: NewHardcodedCredentials, Tags: []string{"misc"}},
+	{ID: "G102", Description: "Bind to all interfaces", Create: NewBindsToAllNetworkInterfaces, Tags: []string{"misc"}},
+	{ID: "G103", Description: "Audit the use of unsafe block", Create: NewUsingUnsafe, Tags: []string{"misc"}},
+	{ID: "G104", Description: "Audit errors not checked", Create: NewNoErrorCheck, Tags: []string{"misc"}},
+	{ID: "G106", Description: "Audit the use of ssh.InsecureIgnoreHostKey function", Create: NewSSHHostKey, Tags: []string{"misc"}},
+	{ID: "G107", Description: "Url provided to HTTP request as taint input", Create: NewSSRFCheck, Tags: []string{"misc"}},
+	{ID: "G108", Description: "Profiling endpoint is automatically exposed", Create: NewPprofCheck, Tags: []string{"misc"}},
+	{ID: "G109", Description: "Converting strconv.Atoi result to int32/int16", Create: NewIntegerOverflowCheck, Tags: []string{"misc"}},
+	{ID: "G110", Description: "Detect io.Copy instead of io.CopyN when decompression", Create: NewDecompressionBombCheck, Tags: []string{"misc"}},
+
+	// injection
+	{ID: "G201", Description: "SQL query construction using format string", Create: NewSQLStrFormat, Tags: []string{"injection"}},
+	{ID: "G202", Description: "SQL query construction using string concatenation", Create: NewSQLStrConcat, Tags: []string{"injection"}},
+	{ID: "G203", Description: "Use of unescaped data in HTML templates", Create: NewTemplateCheck, Tags: []string{"injection"}},
+	{ID: "G204", Description: "Audit use of command execution", Create: NewSubproc, Tags: []string{"injection"}},
+
+	// filesystem
+	{ID: "G301", Description: "Poor file permissions used when creating a directory", Create: NewMkdirPerms, Tags: []string{"filesystem"}},
+	{ID: "G302", Description: "Poor file permissions used when creation file or using chmod", Create: NewFilePerms, Tags: []string{"filesystem"}},
+	{ID: "G303", Description: "Creating tempfile using a predictable path", Create: NewBadTempFile, Tags: []string{"filesystem"}},
+	{ID: "G304", Description: "File path provided as taint input", Create: NewReadFile, Tags: []string{"filesystem"}},
+	{ID: "G305", Description: "File path traversal when extracting zip archive", Create: NewArchive, Tags: []string{"filesystem"}},
+	{ID: "G306", Description: "Poor file permissions used when writing to a file", Create: NewWritePerms, Tags: []string{"filesystem"}},
+	{ID: "G307", Description: "Unsafe defer call of a method returning an error", Create: NewDeferredClosing, Tags: []string{"filesystem"}},
+
+	// crypto
+	{ID: "G401", Description: "Detect the usage of DES, RC4, MD5 or SHA1", Create: NewUsesWeakCryptography, Tags: []string{"crypto"}},
+	{ID: "G402", Description: "Look for bad TLS connection settings", Create: NewIntermediateTLSCheck, Tags: []string{"crypto"}},
+	{ID: "G403", Description: "Ensure minimum RSA key length of 2048 bits", Create: NewWeakKeyStrength, Tags: []string{"crypto"}},
+	{ID: "G404", Description: "Insecure random number source (rand)", Create: NewWeakRandCheck, Tags: []string{"crypto"}},
+
+	// blocklist
+	{ID: "G501", Description: "Import blocklist: crypto/md5", Create: NewBlocklistedImportMD5, Tags: []string{"blocklist"}},
+	{ID: "G502", Description: "Import blocklist: crypto/des", Create: NewBlocklistedImportDES, Tags: []string{"blocklist"}},
+	{ID: "G503", Description: "Import blocklist: crypto/rc4", Create: NewBlocklistedImportRC4, Tags: []string{"blocklist"}},
+	{ID: "G504", Description: "Import blocklist: net/http/cgi", Create: NewBlocklistedImportCGI, Tags: []string{"blocklist"}},
+	{ID: "G505", Description: "Import blocklist: crypto/sha1", Create: NewBlocklistedImportSHA1, Tags: []string{"blocklist"}},
+
+	// memory safety
+	{ID: "G601", Description: "Implicit memory aliasing in RangeStmt", Create: NewImplicitAliasing, Tags: []string{"memory"}},
+}
+
+var (
+	builtinOnce   sync.Once
+	registryMutex sync.RWMutex
+	registry      []RuleDefinition
+)
+
+// builtinRules returns the definitions of the rules provided by gosec, along with
+// the severity and the confidence of their metadata, so that they cannot drift
+func builtinRules() []RuleDefinition {
+	builtinOnce.Do(func() {
+		for i, def := range builtin {
+			rule, _ := def.Create(def.ID, gosec.NewConfig())
+			if described, ok := rule.(gosec.DescribedRule); ok {
+				meta := described.Describe()
+				builtin[i].Severity, builtin[i].Confidence = meta.Severity, meta.Confidence
+			}
+		}
+	})
+	return builtin
+}
+
+// Register adds a rule to the rules generated by Generate, hence to the rules of
+// the gosec command line built with the package of the rule. It is meant to be
+// called from the init function of that package. The issues of the rule refer
+// to its CWE, which is required. Register panics when the definition is invalid
+// or when its ID is already used.
+func Register(def RuleDefinition) {
+	switch {
+	case def.ID == "":
+		panic("rules: Register called without a rule ID")
+	case !ruleID.MatchString(def.ID):
+		panic(fmt.Sprintf("rules: Register called with the invalid rule ID %q, expected upper case letters followed by at least 3 digits", def.ID))
+	case def.Create == nil:
+		panic(fmt.Sprintf("rules: Register called without a builder for %s", def.ID))
+	case def.CWE == "":
+		panic(fmt.Sprintf("rules: Register called without a CWE for %s", def.ID))
 	}
 
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	for _, rule := range append(builtinRules(), registry...) {
+		if rule.ID == def.ID {
+			panic(fmt.Sprintf("rules: Register called twice for %s", def.ID))
+		}
+	}
+	registry = append(registry, def)
+	gosec.RegisterCwe(def.ID, def.CWE)
+}
+
+// Generate the list of rules to use, which are the rules provided by gosec and
+// the registered rules
+func Generate(filters ...RuleFilter) RuleList {
+	registryMutex.RLock()
+	rules := append(append([]RuleDefinition{}, builtinRules()...), registry...)
+	registryMutex.RUnlock()
+
 	ruleMap := make(map[string]RuleDefinition)
 
 RULES:
@@ -110,6 +180,11 @@ RULES:
 				continue RULES
 			}
 		}
+		if rule.CWE == "" {
+			if weakness := gosec.GetCweByRule(rule.ID); weakness != nil {
+				rule.CWE = weakness.ID
+			}
+		}
 		ruleMap[rule.ID] = rule
 	}
 	return ruleMap
@@ -0,0 +1,113 @@
+package rules_test
+
+import (
+	"go/ast"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+// exitCall is a custom rule flagging the calls to os.Exit
+type exitCall struct {
+	gosec.MetaData
+}
+
+func (r *exitCall) ID() string {
+	return r.MetaData.ID
+}
+
+func (r *exitCall) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	if _, matches := gosec.MatchCallByPackage(n, c, "os", "Exit"); matches {
+		return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+	}
+	return nil, nil
+}
+
+func newExitCall(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	return &exitCall{
+		MetaData: gosec.MetaData{
+			ID:         id,
+			What:       "Exit without running the deferred calls",
+			Severity:   gosec.Low,
+			Confidence: gosec.High,
+		},
+	}, []ast.Node{(*ast.CallExpr)(nil)}
+}
+
+// registerOnce registers the custom rule once for all the specs
+var registerOnce sync.Once
+
+var _ = Describe("Rule registry", func() {
+	exitRule := rules.RuleDefinition{
+		ID:          "X101",
+		Description: "Audit the calls to os.Exit",
+		Create:      newExitCall,
+		Severity:    gosec.Low,
+		Confidence:  gosec.High,
+		CWE:         "705",
+		Tags:        []string{"company"},
+	}
+
+	BeforeEach(func() {
+		registerOnce.Do(func() { rules.Register(exitRule) })
+	})
+
+	It("should generate the registered rules along with the gosec rules", func() {
+		ruleList := rules.Generate()
+		Expect(ruleList).To(HaveKey("X101"))
+		Expect(ruleList["X101"].Tags).To(Equal([]string{"company"}))
+		Expect(ruleList["G401"].CWE).To(Equal("326"))
+		Expect(ruleList["G401"].Tags).To(Equal([]string{"crypto"}))
+		Expect(rules.Generate(rules.NewRuleFilter(true, "X101"))).NotTo(HaveKey("X101"))
+
+		Expect(func() { rules.Register(exitRule) }).To(PanicWith("rules: Register called twice for X101"))
+		duplicate := exitRule
+		duplicate.ID = "G101"
+		Expect(func() { rules.Register(duplicate) }).To(Panic())
+		noCWE := exitRule
+		noCWE.ID, noCWE.CWE = "X102", ""
+		Expect(func() { rules.Register(noCWE) }).To(Panic())
+		for _, id := range []string{"x103", "X10", "X103a", "SHA-256"} {
+			invalid := exitRule
+			invalid.ID = id
+			Expect(func() { rules.Register(invalid) }).To(Panic())
+		}
+	})
+
+	It("should take the severity and the confidence of the gosec rules from their metadata", func() {
+		ruleList := rules.Generate()
+		Expect(ruleList["G101"].Severity).To(Equal(gosec.High))
+		Expect(ruleList["G101"].Confidence).To(Equal(gosec.Low))
+		Expect(ruleList["G204"].Severity).To(Equal(gosec.Medium))
+		Expect(ruleList["G204"].Confidence).To(Equal(gosec.High))
+		Expect(ruleList["G402"].Severity).To(Equal(gosec.High))
+		Expect(ruleList["G402"].Confidence).To(Equal(gosec.High))
+	})
+
+	It("should report the issues of the registered rules with their CWE", func() {
+		pkg := testutils.NewTestPackage()
+		defer pkg.Close()
+		pkg.AddFile("main.go", `
+			package main
+			import "os"
+			func main() {
+				os.Exit(2)
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+
+		logger, _ := testutils.NewLogger()
+		analyzer := gosec.NewAnalyzer(nil, false, logger)
+		analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "X101")).Builders())
+		Expect(analyzer.Process(nil, pkg.Path)).Should(Succeed())
+		issues, _, _ := analyzer.Report()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].RuleID).To(Equal("X101"))
+		Expect(issues[0].Cwe.ID).To(Equal("705"))
+		Expect(issues[0].Cwe.SprintURL()).To(Equal("https://cwe.mitre.org/data/definitions/705.html"))
+	})
+})
@@ -79,6 +79,10 @@ var _ = Describe("gosec rules", func() {
 			runner("G107", testutils.SampleCodeG107)
 		})
 
+		It("should detect ssrf via http requests with tainted url", func() {
+			runner("G107", testutils.SampleCodeG107Taint)
+		})
+
 		It("should detect pprof endpoint", func() {
 			runner("G108", testutils.SampleCodeG108)
 		})
@@ -95,6 +99,10 @@ var _ = Describe("gosec rules", func() {
 			runner("G201", testutils.SampleCodeG201)
 		})
 
+		It("should detect sql injection via format strings with tainted input", func() {
+			runner("G201", testutils.SampleCodeG201Taint)
+		})
+
 		It("should detect sql injection via string concatenation", func() {
 			runner("G202", testutils.SampleCodeG202)
 		})
@@ -107,6 +115,10 @@ var _ = Describe("gosec rules", func() {
 			runner("G204", testutils.SampleCodeG204)
 		})
 
+		It("should detect command execution with tainted input", func() {
+			runner("G204", testutils.SampleCodeG204Taint)
+		})
+
 		It("should detect poor file permissions on mkdir", func() {
 			runner("G301", testutils.SampleCodeG301)
 		})
@@ -123,6 +135,10 @@ var _ = Describe("gosec rules", func() {
 			runner("G304", testutils.SampleCodeG304)
 		})
 
+		It("should detect file path provided as tainted input", func() {
+			runner("G304", testutils.SampleCodeG304Taint)
+		})
+
 		It("should detect file path traversal when extracting zip archive", func() {
 			runner("G305", testutils.SampleCodeG305)
 		})
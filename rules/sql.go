@@ -44,6 +44,28 @@ func (s *sqlStatement) MatchPatterns(str string) bool {
 	return true
 }
 
+// isSink checks if the call is a taint sink of the rule
+func (s *sqlStatement) isSink(call *ast.CallExpr, ctx *gosec.Context) bool {
+	_, ok := gosec.TaintSinkArgs(s.ID(), call, ctx)
+	return ok
+}
+
+// queryIndex returns the index of the query argument of the call, which is taken
+// from the taint sinks of the rule when the taint analysis is enabled
+func (s *sqlStatement) queryIndex(call *ast.CallExpr, ctx *gosec.Context) (int, error) {
+	if args, ok := gosec.TaintSinkArgs(s.ID(), call, ctx); ok && len(args) > 0 {
+		return args[0], nil
+	}
+	_, fnName, err := gosec.GetCallInfo(call, ctx)
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasSuffix(fnName, "Context") {
+		return 1, nil
+	}
+	return 0, nil
+}
+
 type sqlStrConcat struct {
 	sqlStatement
 }
@@ -69,15 +91,17 @@ func (s *sqlStrConcat) checkObject(n *ast.Ident, c *gosec.Context) bool {
 
 // checkQuery verifies if the query parameters is a string concatenation
 func (s *sqlStrConcat) checkQuery(call *ast.CallExpr, ctx *gosec.Context) (*gosec.Issue, error) {
-	_, fnName, err := gosec.GetCallInfo(call, ctx)
+	queryIndex, err := s.queryIndex(call, ctx)
 	if err != nil {
 		return nil, err
 	}
-	var query ast.Node
-	if strings.HasSuffix(fnName, "Context") {
-		query = call.Args[1]
-	} else {
-		query = call.Args[0]
+	if queryIndex >= len(call.Args) {
+		return nil, nil
+	}
+	query := call.Args[queryIndex]
+
+	if tainted, ok := gosec.IsTaintedSink(s.ID(), call, ctx); ok && !tainted {
+		return nil, nil
 	}
 
 	if be, ok := query.(*ast.BinaryExpr); ok {
@@ -108,12 +132,12 @@ func (s *sqlStrConcat) Match(n ast.Node, ctx *gosec.Context) (*gosec.Issue, erro
 	switch stmt := n.(type) {
 	case *ast.AssignStmt:
 		for _, expr := range stmt.Rhs {
-			if sqlQueryCall, ok := expr.(*ast.CallExpr); ok && s.ContainsCallExpr(expr, ctx) != nil {
+			if sqlQueryCall, ok := expr.(*ast.CallExpr); ok && (s.ContainsCallExpr(expr, ctx) != nil || s.isSink(sqlQueryCall, ctx)) {
 				return s.checkQuery(sqlQueryCall, ctx)
 			}
 		}
 	case *ast.ExprStmt:
-		if sqlQueryCall, ok := stmt.X.(*ast.CallExpr); ok && s.ContainsCallExpr(stmt.X, ctx) != nil {
+		if sqlQueryCall, ok := stmt.X.(*ast.CallExpr); ok && (s.ContainsCallExpr(stmt.X, ctx) != nil || s.isSink(sqlQueryCall, ctx)) {
 			return s.checkQuery(sqlQueryCall, ctx)
 		}
 	}
@@ -171,15 +195,17 @@ func (s *sqlStrFormat) constObject(e ast.Expr, c *gosec.Context) bool {
 }
 
 func (s *sqlStrFormat) checkQuery(call *ast.CallExpr, ctx *gosec.Context) (*gosec.Issue, error) {
-	_, fnName, err := gosec.GetCallInfo(call, ctx)
+	queryIndex, err := s.queryIndex(call, ctx)
 	if err != nil {
 		return nil, err
 	}
-	var query ast.Node
-	if strings.HasSuffix(fnName, "Context") {
-		query = call.Args[1]
-	} else {
-		query = call.Args[0]
+	if queryIndex >= len(call.Args) {
+		return nil, nil
+	}
+	query := call.Args[queryIndex]
+
+	if tainted, ok := gosec.IsTaintedSink(s.ID(), call, ctx); ok && !tainted {
+		return nil, nil
 	}
 
 	if ident, ok := query.(*ast.Ident); ok && ident.Obj != nil {
@@ -261,12 +287,12 @@ func (s *sqlStrFormat) Match(n ast.Node, ctx *gosec.Context) (*gosec.Issue, erro
 	switch stmt := n.(type) {
 	case *ast.AssignStmt:
 		for _, expr := range stmt.Rhs {
-			if sqlQueryCall, ok := expr.(*ast.CallExpr); ok && s.ContainsCallExpr(expr, ctx) != nil {
+			if sqlQueryCall, ok := expr.(*ast.CallExpr); ok && (s.ContainsCallExpr(expr, ctx) != nil || s.isSink(sqlQueryCall, ctx)) {
 				return s.checkQuery(sqlQueryCall, ctx)
 			}
 		}
 	case *ast.ExprStmt:
-		if sqlQueryCall, ok := stmt.X.(*ast.CallExpr); ok && s.ContainsCallExpr(stmt.X, ctx) != nil {
+		if sqlQueryCall, ok := stmt.X.(*ast.CallExpr); ok && (s.ContainsCallExpr(stmt.X, ctx) != nil || s.isSink(sqlQueryCall, ctx)) {
 			return s.checkQuery(sqlQueryCall, ctx)
 		}
 	}
@@ -40,6 +40,14 @@ func (r *subprocess) ID() string {
 //
 // syscall.Exec("echo", "foobar" + tainted)
 func (r *subprocess) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	if call, ok := n.(*ast.CallExpr); ok {
+		if tainted, ok := gosec.IsTaintedSink(r.ID(), call, c); ok {
+			if tainted {
+				return gosec.NewIssue(c, n, r.ID(), "Subprocess launched with tainted input", gosec.Medium, gosec.High), nil
+			}
+			return nil, nil
+		}
+	}
 	if node := r.ContainsPkgCallExpr(n, c, false); node != nil {
 		args := node.Args
 		if r.isContext(n, c) {
@@ -75,7 +83,7 @@ func (r *subprocess) isContext(n ast.Node, ctx *gosec.Context) bool {
 
 // NewSubproc detects cases where we are forking out to an external process
 func NewSubproc(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
-	rule := &subprocess{gosec.MetaData{ID: id}, gosec.NewCallList()}
+	rule := &subprocess{gosec.MetaData{ID: id, Severity: gosec.Medium, Confidence: gosec.High}, gosec.NewCallList()}
 	rule.Add("os/exec", "Command")
 	rule.Add("os/exec", "CommandContext")
 	rule.Add("syscall", "Exec")
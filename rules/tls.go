@@ -47,41 +47,42 @@ func stringInSlice(a string, list []string) bool {
 	return false
 }
 
-func (t *insecureConfigTLS) processTLSCipherSuites(n ast.Node, c *gosec.Context) *gosec.Issue {
+func (t *insecureConfigTLS) processTLSCipherSuites(n ast.Node, c *gosec.Context) []*gosec.Issue {
+	var issues []*gosec.Issue
 	if ciphers, ok := n.(*ast.CompositeLit); ok {
 		for _, cipher := range ciphers.Elts {
 			if ident, ok := cipher.(*ast.SelectorExpr); ok {
 				if !stringInSlice(ident.Sel.Name, t.goodCiphers) {
 					err := fmt.Sprintf("TLS Bad Cipher Suite: %s", ident.Sel.Name)
-					return gosec.NewIssue(c, ident, t.ID(), err, gosec.High, gosec.High)
+					issues = append(issues, gosec.NewIssue(c, ident, t.ID(), err, gosec.High, gosec.High))
 				}
 			}
 		}
 	}
-	return nil
+	return issues
 }
 
-func (t *insecureConfigTLS) processTLSConfVal(n *ast.KeyValueExpr, c *gosec.Context) *gosec.Issue {
+func (t *insecureConfigTLS) processTLSConfVal(n *ast.KeyValueExpr, c *gosec.Context) []*gosec.Issue {
 	if ident, ok := n.Key.(*ast.Ident); ok {
 		switch ident.Name {
 		case "InsecureSkipVerify":
 			if node, ok := n.Value.(*ast.Ident); ok {
 				if node.Name != "false" {
-					return gosec.NewIssue(c, n, t.ID(), "TLS InsecureSkipVerify set true.", gosec.High, gosec.High)
+					return []*gosec.Issue{gosec.NewIssue(c, n, t.ID(), "TLS InsecureSkipVerify set true.", gosec.High, gosec.High)}
 				}
 			} else {
 				// TODO(tk): symbol tab look up to get the actual value
-				return gosec.NewIssue(c, n, t.ID(), "TLS InsecureSkipVerify may be true.", gosec.High, gosec.Low)
+				return []*gosec.Issue{gosec.NewIssue(c, n, t.ID(), "TLS InsecureSkipVerify may be true.", gosec.High, gosec.Low)}
 			}
 
 		case "PreferServerCipherSuites":
 			if node, ok := n.Value.(*ast.Ident); ok {
 				if node.Name == "false" {
-					return gosec.NewIssue(c, n, t.ID(), "TLS PreferServerCipherSuites set false.", gosec.Medium, gosec.High)
+					return []*gosec.Issue{gosec.NewIssue(c, n, t.ID(), "TLS PreferServerCipherSuites set false.", gosec.Medium, gosec.High)}
 				}
 			} else {
 				// TODO(tk): symbol tab look up to get the actual value
-				return gosec.NewIssue(c, n, t.ID(), "TLS PreferServerCipherSuites may be false.", gosec.Medium, gosec.Low)
+				return []*gosec.Issue{gosec.NewIssue(c, n, t.ID(), "TLS PreferServerCipherSuites may be false.", gosec.Medium, gosec.Low)}
 			}
 
 		case "MinVersion":
@@ -107,9 +108,7 @@ func (t *insecureConfigTLS) processTLSConfVal(n *ast.KeyValueExpr, c *gosec.Cont
 			}
 
 		case "CipherSuites":
-			if ret := t.processTLSCipherSuites(n.Value, c); ret != nil {
-				return ret
-			}
+			return t.processTLSCipherSuites(n.Value, c)
 
 		}
 	}
@@ -131,18 +130,16 @@ func (t *insecureConfigTLS) mapVersion(version string) int64 {
 	return v
 }
 
-func (t *insecureConfigTLS) checkVersion(n ast.Node, c *gosec.Context) *gosec.Issue {
-	if t.actualMaxVersion == 0 && t.actualMinVersion >= t.MinVersion {
-		// no warning is generated since the min version is greater than the secure min version
-		return nil
-	}
+func (t *insecureConfigTLS) checkVersion(n ast.Node, c *gosec.Context) []*gosec.Issue {
+	var issues []*gosec.Issue
 	if t.actualMinVersion < t.MinVersion {
-		return gosec.NewIssue(c, n, t.ID(), "TLS MinVersion too low.", gosec.High, gosec.High)
+		issues = append(issues, gosec.NewIssue(c, n, t.ID(), "TLS MinVersion too low.", gosec.High, gosec.High))
 	}
-	if t.actualMaxVersion < t.MaxVersion {
-		return gosec.NewIssue(c, n, t.ID(), "TLS MaxVersion too low.", gosec.High, gosec.High)
+	// a zero max version selects the highest version supported
+	if t.actualMaxVersion != 0 && t.actualMaxVersion < t.MaxVersion {
+		issues = append(issues, gosec.NewIssue(c, n, t.ID(), "TLS MaxVersion too low.", gosec.High, gosec.High))
 	}
-	return nil
+	return issues
 }
 
 func (t *insecureConfigTLS) resetVersion() {
@@ -151,20 +148,27 @@ func (t *insecureConfigTLS) resetVersion() {
 }
 
 func (t *insecureConfigTLS) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	issues, err := t.MatchAll(n, c)
+	if len(issues) == 0 {
+		return nil, err
+	}
+	return issues[0], err
+}
+
+// MatchAll reports each insecure setting of the TLS configuration
+func (t *insecureConfigTLS) MatchAll(n ast.Node, c *gosec.Context) ([]*gosec.Issue, error) {
 	if complit, ok := n.(*ast.CompositeLit); ok && complit.Type != nil {
 		actualType := c.Info.TypeOf(complit.Type)
 		if actualType != nil && actualType.String() == t.requiredType {
+			var issues []*gosec.Issue
 			for _, elt := range complit.Elts {
 				if kve, ok := elt.(*ast.KeyValueExpr); ok {
-					issue := t.processTLSConfVal(kve, c)
-					if issue != nil {
-						return issue, nil
-					}
+					issues = append(issues, t.processTLSConfVal(kve, c)...)
 				}
 			}
-			issue := t.checkVersion(complit, c)
+			issues = append(issues, t.checkVersion(complit, c)...)
 			t.resetVersion()
-			return issue, nil
+			return issues, nil
 		}
 	}
 	return nil, nil
@@ -0,0 +1,162 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// Options configures a scan run by Scan
+type Options struct {
+	// Patterns are the paths of the scanned packages, the paths ending with "..."
+	// include the packages of the sub-directories, e.g. "./..."
+	Patterns []string
+	// Rules are the builders of the rules keyed by ID, e.g. rules.Generate().Builders()
+	Rules map[string]RuleBuilder
+	// IncludeRules keeps only the rules with the given IDs, when not empty
+	IncludeRules []string
+	// ExcludeRules removes the rules with the given IDs
+	ExcludeRules []string
+	// Config is the configuration of the analyzer and of the rules
+	Config Config
+	// Severity is the minimum severity of the reported issues
+	Severity Score
+	// Confidence is the minimum confidence of the reported issues
+	Confidence Score
+	// Tests enables the scan of the test files
+	Tests bool
+	// BuildTags are the build tags used to load the packages
+	BuildTags []string
+	// ExcludeDirs are the regular expressions of the excluded directories
+	ExcludeDirs []string
+	// Concurrency is the number of packages checked in parallel, 1 by default
+	Concurrency int
+	// SortIssues sorts the issues by severity in descending order
+	SortIssues bool
+	// TrackSuppressions reports the issues suppressed by #nosec directives
+	TrackSuppressions bool
+	// StaleNosec reports the #nosec directives which suppress no issue
+	StaleNosec bool
+	// ProfileRules records the time spent by the rules and the loads of packages
+	ProfileRules bool
+	// Platforms are the platforms for which the packages are checked, the current
+	// platform when empty
+	Platforms []Platform
+	// Overlay holds the content of the unsaved files, keyed by absolute path
+	Overlay map[string][]byte
+	// Cache reuses the results of the unchanged packages, when not nil
+	Cache *Cache
+	// Logger receives the progress of the scan, stderr when nil
+	Logger *log.Logger
+}
+
+// Scan checks the packages selected by the options and returns the issues which
+// reach the minimum severity and confidence. The scan stops when the context is
+// canceled, and the error of the context is returned.
+func Scan(ctx context.Context, opts Options) (*ReportInfo, error) {
+	scanner, err := NewScanner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Patterns) == 0 {
+		return nil, errors.New("no packages found")
+	}
+	return scanner.Scan(ctx, opts.Patterns...)
+}
+
+// Scanner checks packages with the options of a scan, except the patterns. The
+// rules are selected and loaded once, so that the scanner can check packages
+// several times, e.g. each time they change.
+type Scanner struct {
+	opts     Options
+	analyzer *Analyzer
+	failures []*RuleFailure // the failures of the rules which couldn't be loaded
+}
+
+// NewScanner creates a scanner with the rules and the settings of the options
+func NewScanner(opts Options) (*Scanner, error) {
+	builders := selectRules(opts.Rules, opts.IncludeRules, opts.ExcludeRules)
+	if len(builders) == 0 {
+		return nil, errors.New("no rules are configured")
+	}
+
+	analyzer := NewAnalyzer(opts.Config, opts.Tests, opts.Logger)
+	if opts.Concurrency > 0 {
+		analyzer.SetConcurrency(opts.Concurrency)
+	}
+	analyzer.SetTrackSuppressions(opts.TrackSuppressions)
+	analyzer.SetStaleNosec(opts.StaleNosec)
+	analyzer.SetProfileRules(opts.ProfileRules)
+	analyzer.SetPlatforms(opts.Platforms)
+	analyzer.SetExcludedDirs(ExcludedDirsRegExp(opts.ExcludeDirs))
+	if opts.Overlay != nil {
+		analyzer.SetOverlay(opts.Overlay)
+	}
+	if opts.Cache != nil {
+		analyzer.SetCache(opts.Cache)
+	}
+	analyzer.LoadRules(builders)
+	return &Scanner{
+		opts:     opts,
+		analyzer: analyzer,
+		failures: append([]*RuleFailure{}, analyzer.RuleFailures()...),
+	}, nil
+}
+
+// Scan checks the packages matched by the given patterns and returns the issues
+// which reach the minimum severity and confidence. The scan stops when the context
+// is canceled, and the error of the context is returned.
+func (s *Scanner) Scan(ctx context.Context, patterns ...string) (*ReportInfo, error) {
+	analyzer := s.analyzer
+	defer func() {
+		// the results are cleared for the next scan
+		analyzer.drain()
+		analyzer.failures = append([]*RuleFailure{}, s.failures...)
+	}()
+	if err := analyzer.ProcessContext(ctx, s.opts.BuildTags, patterns...); err != nil {
+		return nil, err
+	}
+
+	issues, metrics, errs := analyzer.Report()
+	if s.opts.SortIssues {
+		SortIssues(issues)
+	}
+	issues = FilterIssues(issues, s.opts.Severity, s.opts.Confidence)
+	suppressed := FilterIssues(analyzer.Suppressed(), s.opts.Severity, s.opts.Confidence)
+	metrics.NumFound = len(issues)
+	return NewReportInfo(issues, metrics, errs).
+		WithSuppressed(suppressed).
+		WithRuleFailures(analyzer.RuleFailures()), nil
+}
+
+// selectRules returns the builders of the included rules which are not excluded
+func selectRules(builders map[string]RuleBuilder, include, exclude []string) map[string]RuleBuilder {
+	included := make(map[string]bool, len(include))
+	for _, id := range include {
+		included[id] = true
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+	selected := make(map[string]RuleBuilder, len(builders))
+	for id, builder := range builders {
+		if (len(included) == 0 || included[id]) && !excluded[id] {
+			selected[id] = builder
+		}
+	}
+	return selected
+}
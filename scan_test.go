@@ -0,0 +1,101 @@
+package gosec_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec/v2"
+	"github.com/securego/gosec/v2/rules"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Scan", func() {
+	var (
+		pkg  *testutils.TestPackage
+		opts gosec.Options
+	)
+
+	BeforeEach(func() {
+		pkg = testutils.NewTestPackage()
+		pkg.AddFile("main.go", `
+			package main
+			import (
+				"crypto/md5"
+				"os"
+			)
+			func main() {
+				println(md5.Sum(nil)[0])
+				os.Chmod("/tmp/file", 0777)
+			}`)
+		Expect(pkg.Build()).Should(Succeed())
+		logger, _ := testutils.NewLogger()
+		opts = gosec.Options{
+			Patterns: []string{pkg.Path},
+			Rules:    rules.Generate().Builders(),
+			Logger:   logger,
+		}
+	})
+
+	AfterEach(func() {
+		pkg.Close()
+	})
+
+	It("should report the issues of the selected rules", func() {
+		opts.IncludeRules = []string{"G302", "G401", "G501"}
+		opts.ExcludeRules = []string{"G501"}
+		opts.SortIssues = true
+		report, err := gosec.Scan(context.Background(), opts)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(report.Issues).To(HaveLen(2))
+		Expect(report.Issues[0].RuleID).To(Equal("G401"))
+		Expect(report.Issues[1].RuleID).To(Equal("G302"))
+		Expect(report.Stats.NumFound).To(Equal(2))
+	})
+
+	It("should filter the issues by severity and confidence", func() {
+		opts.IncludeRules = []string{"G302", "G401"}
+		opts.Severity = gosec.Medium
+		opts.Confidence = gosec.High
+		report, err := gosec.Scan(context.Background(), opts)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(report.Issues).To(HaveLen(2))
+
+		opts.Severity = gosec.High
+		report, err = gosec.Scan(context.Background(), opts)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(report.Issues).To(BeEmpty())
+		Expect(report.Stats.NumFound).To(Equal(0))
+		Expect(report.Stats.NumFiles).To(Equal(1))
+	})
+
+	It("should scan the packages several times with the same rules", func() {
+		opts.IncludeRules = []string{"G302", "G401"}
+		scanner, err := gosec.NewScanner(opts)
+		Expect(err).ShouldNot(HaveOccurred())
+		for i := 0; i < 2; i++ {
+			report, err := scanner.Scan(context.Background(), pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(report.Issues).To(HaveLen(2))
+			Expect(report.Stats.NumFiles).To(Equal(1))
+		}
+	})
+
+	It("should stop when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := gosec.Scan(ctx, opts)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should fail without rules or packages", func() {
+		opts.IncludeRules = []string{"G000"}
+		_, err := gosec.Scan(context.Background(), opts)
+		Expect(err).To(MatchError("no rules are configured"))
+
+		opts.IncludeRules = nil
+		opts.Patterns = nil
+		_, err = gosec.Scan(context.Background(), opts)
+		Expect(err).To(MatchError("no packages found"))
+	})
+})
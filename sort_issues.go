@@ -1,11 +1,9 @@
-package main
+package gosec
 
 import (
 	"sort"
 	"strconv"
 	"strings"
-
-	"github.com/securego/gosec/v2"
 )
 
 // handle ranges
@@ -14,7 +12,7 @@ func extractLineNumber(s string) int {
 	return lineNumber
 }
 
-type sortBySeverity []*gosec.Issue
+type sortBySeverity []*Issue
 
 func (s sortBySeverity) Len() int { return len(s) }
 
@@ -33,7 +31,18 @@ func (s sortBySeverity) Less(i, j int) bool {
 
 func (s sortBySeverity) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-// sortIssues sorts the issues by severity in descending order
-func sortIssues(issues []*gosec.Issue) {
+// SortIssues sorts the issues by severity in descending order
+func SortIssues(issues []*Issue) {
 	sort.Sort(sortBySeverity(issues))
 }
+
+// FilterIssues returns the issues with at least the given severity and confidence
+func FilterIssues(issues []*Issue, severity Score, confidence Score) []*Issue {
+	result := []*Issue{}
+	for _, issue := range issues {
+		if issue.Severity >= severity && issue.Confidence >= confidence {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
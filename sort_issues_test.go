@@ -1,8 +1,6 @@
-package main
+package gosec_test
 
 import (
-	"testing"
-
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/securego/gosec/v2"
@@ -24,15 +22,10 @@ func createIssue() gosec.Issue {
 	return defaultIssue
 }
 
-func TestRules(t *testing.T) {
-	RegisterFailHandler(Fail)
-	RunSpecs(t, "Sort issues Suite")
-}
-
 func firstIsGreater(less, greater *gosec.Issue) {
 	slice := []*gosec.Issue{less, greater}
 
-	sortIssues(slice)
+	gosec.SortIssues(slice)
 
 	ExpectWithOffset(0, slice[0]).To(Equal(greater))
 }
@@ -0,0 +1,199 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taint
+
+import (
+	"go/types"
+)
+
+// Config defines the sources, sanitizers and sinks of the taint analysis.
+// Functions are identified by their full name, e.g. "os.Getenv" or
+// "(*net/http.Request).FormValue", and globals by their package path and
+// name, e.g. "os.Args".
+type Config struct {
+	// SourceFuncs are the functions returning tainted data
+	SourceFuncs []string `json:"source_funcs"`
+	// SourceGlobals are the package level variables holding tainted data
+	SourceGlobals []string `json:"source_globals"`
+	// SourceTypes are the types whose values are tainted when received as
+	// parameters, e.g. "*net/http.Request"
+	SourceTypes []string `json:"source_types"`
+	// Sanitizers are the functions returning untainted data regardless of
+	// their arguments
+	Sanitizers []string `json:"sanitizers"`
+	// Sinks maps the rule IDs to the functions which must not receive tainted
+	// data and the indices of their checked parameters. A nil list checks all
+	// the parameters and the index of a variadic parameter covers all its
+	// arguments.
+	Sinks map[string]map[string][]int `json:"sinks"`
+}
+
+// lookup indexes the configuration for the queries
+type lookup struct {
+	sourceFuncs   map[string]bool
+	sourceGlobals map[string]bool
+	sourceTypes   map[string]bool
+	sanitizers    map[string]bool
+	sinks         map[string]map[string][]int
+}
+
+// DefaultConfig returns a configuration which considers the user input received
+// from the command line, the environment and HTTP requests as tainted
+func DefaultConfig() *Config {
+	return &Config{
+		SourceFuncs: []string{
+			"os.Getenv",
+			"os.LookupEnv",
+			"os.Environ",
+			"flag.Arg",
+			"flag.Args",
+			"io/ioutil.ReadAll",
+			"(*bufio.Reader).ReadString",
+			"(*bufio.Reader).ReadLine",
+			"(*bufio.Reader).ReadBytes",
+			"(*bufio.Scanner).Text",
+			"(*bufio.Scanner).Bytes",
+			"(*net/http.Request).FormValue",
+			"(*net/http.Request).PostFormValue",
+			"(*net/http.Request).Cookie",
+			"(*net/http.Request).Cookies",
+			"(*net/http.Request).Referer",
+			"(*net/http.Request).UserAgent",
+		},
+		SourceGlobals: []string{
+			"os.Args",
+			"os.Stdin",
+		},
+		SourceTypes: []string{
+			"*net/http.Request",
+			"*net/url.URL",
+			"net/url.Values",
+		},
+		Sanitizers: []string{
+			"path/filepath.Base",
+			"path/filepath.Rel",
+			"strconv.Atoi",
+			"strconv.ParseBool",
+			"strconv.ParseFloat",
+			"strconv.ParseInt",
+			"strconv.ParseUint",
+			"strconv.Quote",
+			"html.EscapeString",
+			"net/url.PathEscape",
+			"net/url.QueryEscape",
+			"github.com/lib/pq.QuoteIdentifier",
+			"github.com/lib/pq.QuoteLiteral",
+		},
+		Sinks: map[string]map[string][]int{
+			"G107": {
+				"net/http.Get":      {0},
+				"net/http.Head":     {0},
+				"net/http.Post":     {0},
+				"net/http.PostForm": {0},
+			},
+			"G201": sqlSinks(),
+			"G202": sqlSinks(),
+			"G204": {
+				"os/exec.Command":                         nil,
+				"os/exec.CommandContext":                  {1, 2},
+				"syscall.Exec":                            nil,
+				"syscall.ForkExec":                        nil,
+				"syscall.StartProcess":                    nil,
+				"golang.org/x/sys/execabs.Command":        nil,
+				"golang.org/x/sys/execabs.CommandContext": {1, 2},
+			},
+			"G304": {
+				"os.Open":            {0},
+				"os.OpenFile":        {0},
+				"io/ioutil.ReadFile": {0},
+			},
+		},
+	}
+}
+
+// sqlSinks returns the query parameters of the database/sql package
+func sqlSinks() map[string][]int {
+	sinks := make(map[string][]int)
+	for _, recv := range []string{"(*database/sql.DB)", "(*database/sql.Tx)"} {
+		sinks[recv+".Query"] = []int{0}
+		sinks[recv+".QueryRow"] = []int{0}
+		sinks[recv+".QueryContext"] = []int{1}
+		sinks[recv+".QueryRowContext"] = []int{1}
+	}
+	return sinks
+}
+
+// Merge appends the sources, sanitizers and sinks from other to the configuration
+func (c *Config) Merge(other *Config) {
+	c.SourceFuncs = append(c.SourceFuncs, other.SourceFuncs...)
+	c.SourceGlobals = append(c.SourceGlobals, other.SourceGlobals...)
+	c.SourceTypes = append(c.SourceTypes, other.SourceTypes...)
+	c.Sanitizers = append(c.Sanitizers, other.Sanitizers...)
+	if c.Sinks == nil {
+		c.Sinks = make(map[string]map[string][]int)
+	}
+	for rule, funcs := range other.Sinks {
+		if c.Sinks[rule] == nil {
+			c.Sinks[rule] = make(map[string][]int)
+		}
+		for name, params := range funcs {
+			c.Sinks[rule][name] = params
+		}
+	}
+}
+
+func newLookup(c *Config) *lookup {
+	sinks := make(map[string]map[string][]int, len(c.Sinks))
+	for rule, funcs := range c.Sinks {
+		sinks[rule] = make(map[string][]int, len(funcs))
+		for name, params := range funcs {
+			sinks[rule][name] = params
+		}
+	}
+	return &lookup{
+		sourceFuncs:   toSet(c.SourceFuncs),
+		sourceGlobals: toSet(c.SourceGlobals),
+		sourceTypes:   toSet(c.SourceTypes),
+		sanitizers:    toSet(c.Sanitizers),
+		sinks:         sinks,
+	}
+}
+
+func (l *lookup) isSourceFunc(name string) bool {
+	return l.sourceFuncs[name]
+}
+
+func (l *lookup) isSourceGlobal(name string) bool {
+	return l.sourceGlobals[name]
+}
+
+func (l *lookup) isSourceType(t types.Type) bool {
+	return l.sourceTypes[t.String()]
+}
+
+func (l *lookup) isSanitizer(name string) bool {
+	return l.sanitizers[name]
+}
+
+func (l *lookup) sinkParams(rule string, name string) ([]int, bool) {
+	params, ok := l.sinks[rule][name]
+	return params, ok
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,543 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package taint implements a taint analysis built on the SSA form of a package.
+// It tracks the data flowing from configurable sources (e.g. os.Args or the fields
+// of an *http.Request) through assignments, function calls, returns, struct fields
+// and closures, up to the arguments of a call.
+package taint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Analyzer answers taint queries about the calls of a single package. The SSA
+// form of the package is built lazily on the first query.
+type Analyzer struct {
+	config *lookup
+	fset   *token.FileSet
+	pkg    *types.Package
+	files  []*ast.File
+	info   *types.Info
+
+	once      sync.Once
+	mu        sync.Mutex
+	err       error
+	functions []*ssa.Function
+	calls     map[token.Pos]ssa.CallInstruction
+	callers   map[*ssa.Function][]ssa.CallInstruction
+	closures  map[*ssa.Function][]*ssa.MakeClosure
+	globals   map[*ssa.Global][]ssa.Instruction
+	// escaping holds the functions used as values, whose callers are unknown
+	escaping map[*ssa.Function]bool
+	// invoked holds the names of the methods called through an interface
+	invoked map[string]bool
+}
+
+// New creates a taint analyzer for the given type-checked package
+func New(config *Config, fset *token.FileSet, pkg *types.Package, files []*ast.File, info *types.Info) *Analyzer {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &Analyzer{
+		config: newLookup(config),
+		fset:   fset,
+		pkg:    pkg,
+		files:  files,
+		info:   info,
+	}
+}
+
+// IsTainted reports whether tainted data reaches the argument with the given
+// index of the call. The index is relative to call.Args.
+func (a *Analyzer) IsTainted(call *ast.CallExpr, arg int) (bool, error) {
+	if err := a.build(); err != nil {
+		return false, err
+	}
+	if arg < 0 || arg >= len(call.Args) {
+		return false, fmt.Errorf("argument %d out of range", arg)
+	}
+	instr, ok := a.calls[call.Lparen]
+	if !ok {
+		return false, fmt.Errorf("call at %s not found in SSA form", a.fset.Position(call.Pos()))
+	}
+	index := a.argIndex(call, instr.Common(), arg)
+	args := instr.Common().Args
+	if index >= len(args) {
+		return false, fmt.Errorf("argument %d not found in SSA form", arg)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return newQuery(a).tainted(args[index]), nil
+}
+
+// SinkArgs returns the indices of the arguments of the call checked by the sinks
+// of the given rule. The indices are relative to call.Args. The second value is
+// false when the callee is not a sink of the rule.
+func (a *Analyzer) SinkArgs(rule string, call *ast.CallExpr) ([]int, bool) {
+	fn := typeutil.StaticCallee(a.info, call)
+	if fn == nil {
+		return nil, false
+	}
+	params, ok := a.config.sinkParams(rule, fn.FullName())
+	if !ok {
+		return nil, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if params == nil {
+		for i := 0; i < sig.Params().Len(); i++ {
+			params = append(params, i)
+		}
+	}
+	var args []int
+	for _, param := range params {
+		if param < 0 || param >= len(call.Args) {
+			continue
+		}
+		if sig.Variadic() && param == sig.Params().Len()-1 {
+			for i := param; i < len(call.Args); i++ {
+				args = append(args, i)
+			}
+			continue
+		}
+		args = append(args, param)
+	}
+	return args, true
+}
+
+// build creates the SSA form of the package and indexes its calls
+func (a *Analyzer) build() error {
+	a.once.Do(func() {
+		defer func() {
+			// The SSA builder panics on code it does not support
+			if r := recover(); r != nil {
+				a.err = fmt.Errorf("building SSA form of package %q: %v", a.pkg.Path(), r)
+			}
+		}()
+		prog := ssa.NewProgram(a.fset, 0)
+		created := make(map[*types.Package]bool)
+		var createImports func(pkgs []*types.Package)
+		createImports = func(pkgs []*types.Package) {
+			for _, p := range pkgs {
+				if created[p] {
+					continue
+				}
+				created[p] = true
+				prog.CreatePackage(p, nil, nil, true)
+				createImports(p.Imports())
+			}
+		}
+		created[a.pkg] = true
+		createImports(a.pkg.Imports())
+		ssaPkg := prog.CreatePackage(a.pkg, a.files, a.info, false)
+		ssaPkg.Build()
+		a.index(prog, ssaPkg)
+	})
+	return a.err
+}
+
+// index collects the functions of the package along with their calls
+func (a *Analyzer) index(prog *ssa.Program, pkg *ssa.Package) {
+	seen := make(map[*ssa.Function]bool)
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		if fn == nil || seen[fn] || fn.Pkg != pkg {
+			return
+		}
+		seen[fn] = true
+		a.functions = append(a.functions, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	for _, member := range pkg.Members {
+		switch m := member.(type) {
+		case *ssa.Function:
+			add(m)
+		case *ssa.Type:
+			for _, t := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+				mset := prog.MethodSets.MethodSet(t)
+				for i := 0; i < mset.Len(); i++ {
+					add(prog.MethodValue(mset.At(i)))
+				}
+			}
+		}
+	}
+
+	a.calls = make(map[token.Pos]ssa.CallInstruction)
+	a.callers = make(map[*ssa.Function][]ssa.CallInstruction)
+	a.closures = make(map[*ssa.Function][]*ssa.MakeClosure)
+	a.globals = make(map[*ssa.Global][]ssa.Instruction)
+	a.escaping = make(map[*ssa.Function]bool)
+	a.invoked = make(map[string]bool)
+	for _, fn := range a.functions {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				var callee *ssa.Value
+				switch instr := instr.(type) {
+				case ssa.CallInstruction:
+					common := instr.Common()
+					a.calls[common.Pos()] = instr
+					if common.IsInvoke() {
+						a.invoked[common.Method.Name()] = true
+					}
+					if fn := common.StaticCallee(); fn != nil {
+						a.callers[fn] = append(a.callers[fn], instr)
+					}
+					callee = &common.Value
+				case *ssa.MakeClosure:
+					if closure, ok := instr.Fn.(*ssa.Function); ok {
+						a.closures[closure] = append(a.closures[closure], instr)
+						if !calledOnly(instr) {
+							a.escaping[closure] = true
+						}
+					}
+					callee = &instr.Fn
+				}
+				for _, op := range instr.Operands(nil) {
+					switch v := (*op).(type) {
+					case *ssa.Global:
+						// the referrers of globals are not tracked by the SSA form
+						a.globals[v] = append(a.globals[v], instr)
+					case *ssa.Function:
+						if op != callee {
+							a.escaping[v] = true
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// calledOnly reports whether the closure is only used as the callee of calls
+func calledOnly(closure *ssa.MakeClosure) bool {
+	refs := closure.Referrers()
+	if refs == nil {
+		return true
+	}
+	for _, ref := range *refs {
+		call, ok := ref.(ssa.CallInstruction)
+		if !ok || call.Common().Value != closure || call.Common().IsInvoke() {
+			return false
+		}
+	}
+	return true
+}
+
+// unknownCallers reports whether the function might be called from outside the
+// package or through a value, in which case its parameters are not tracked
+func (a *Analyzer) unknownCallers(fn *ssa.Function) bool {
+	if obj, ok := fn.Object().(*types.Func); ok && obj.Exported() {
+		return true
+	}
+	if fn.Signature.Recv() != nil && a.invoked[fn.Name()] {
+		return true
+	}
+	return a.escaping[fn] || len(a.callers[fn]) == 0
+}
+
+// argIndex maps the index of an argument in the AST to its index in the SSA call
+func (a *Analyzer) argIndex(call *ast.CallExpr, common *ssa.CallCommon, arg int) int {
+	index := arg
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && !common.IsInvoke() {
+		if selection, ok := a.info.Selections[sel]; ok && selection.Kind() == types.MethodVal {
+			// the receiver is passed as first argument of the SSA call
+			index++
+		}
+	}
+	if sig := common.Signature(); sig != nil && sig.Variadic() && !call.Ellipsis.IsValid() {
+		last := sig.Params().Len() - 1
+		if sig.Recv() != nil && !common.IsInvoke() {
+			last++
+		}
+		if index > last {
+			index = last
+		}
+	}
+	return index
+}
+
+// query keeps the values visited while answering a single taint query
+type query struct {
+	analyzer *Analyzer
+	visited  map[ssa.Value]bool
+}
+
+func newQuery(a *Analyzer) *query {
+	return &query{analyzer: a, visited: make(map[ssa.Value]bool)}
+}
+
+// tainted walks backward the data flow of the value looking for a source
+func (q *query) tainted(v ssa.Value) bool {
+	if v == nil {
+		return false
+	}
+	if result, ok := q.visited[v]; ok {
+		return result
+	}
+	// values in a cycle are considered untainted until proved otherwise
+	q.visited[v] = false
+	result := q.taintedValue(v)
+	q.visited[v] = result
+	return result
+}
+
+func (q *query) taintedValue(v ssa.Value) bool {
+	conf := q.analyzer.config
+	if conf.isSourceType(v.Type()) {
+		if _, ok := v.(*ssa.Parameter); ok {
+			return true
+		}
+	}
+
+	switch v := v.(type) {
+	case *ssa.Const, *ssa.Function, *ssa.Builtin, *ssa.MakeClosure:
+		return false
+	case *ssa.Global:
+		if conf.isSourceGlobal(globalName(v)) {
+			return true
+		}
+		return q.taintedStores(v)
+	case *ssa.Parameter:
+		return q.taintedParameter(v)
+	case *ssa.FreeVar:
+		return q.taintedFreeVar(v)
+	case *ssa.Call:
+		return q.taintedCall(v.Common())
+	case *ssa.Extract:
+		return q.tainted(v.Tuple)
+	case *ssa.BinOp:
+		return q.tainted(v.X) || q.tainted(v.Y)
+	case *ssa.UnOp:
+		return q.tainted(v.X)
+	case *ssa.Phi:
+		for _, edge := range v.Edges {
+			if q.tainted(edge) {
+				return true
+			}
+		}
+		return false
+	case *ssa.Convert:
+		return q.tainted(v.X)
+	case *ssa.ChangeType:
+		return q.tainted(v.X)
+	case *ssa.ChangeInterface:
+		return q.tainted(v.X)
+	case *ssa.MakeInterface:
+		return q.tainted(v.X)
+	case *ssa.TypeAssert:
+		return q.tainted(v.X)
+	case *ssa.Slice:
+		return q.tainted(v.X)
+	case *ssa.Field:
+		return q.tainted(v.X)
+	case *ssa.Index:
+		return q.tainted(v.X)
+	case *ssa.Lookup:
+		return q.tainted(v.X)
+	case *ssa.Range:
+		return q.tainted(v.X)
+	case *ssa.Next:
+		return q.tainted(v.Iter)
+	case *ssa.FieldAddr:
+		return q.tainted(v.X) || q.taintedStores(v)
+	case *ssa.IndexAddr:
+		return q.tainted(v.X) || q.taintedStores(v)
+	case *ssa.Alloc, *ssa.MakeSlice, *ssa.MakeMap:
+		return q.taintedStores(v)
+	}
+	return false
+}
+
+// taintedStores checks the values stored at the given address
+func (q *query) taintedStores(addr ssa.Value) bool {
+	var refs []ssa.Instruction
+	if g, ok := addr.(*ssa.Global); ok {
+		refs = q.analyzer.globals[g]
+	} else if r := addr.Referrers(); r != nil {
+		refs = *r
+	}
+	for _, ref := range refs {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Addr == addr && q.tainted(ref.Val) {
+				return true
+			}
+		case *ssa.MapUpdate:
+			if ref.Map == addr && (q.tainted(ref.Key) || q.tainted(ref.Value)) {
+				return true
+			}
+		case *ssa.FieldAddr:
+			if ref.X == addr && q.taintedStores(ref) {
+				return true
+			}
+		case *ssa.IndexAddr:
+			if ref.X == addr && q.taintedStores(ref) {
+				return true
+			}
+		case *ssa.Slice:
+			if ref.X == addr && q.taintedStores(ref) {
+				return true
+			}
+		case ssa.CallInstruction:
+			// the address might be written by the callee, e.g. fmt.Sscan(input, &value)
+			common := ref.Common()
+			if callee := common.StaticCallee(); callee != nil && len(callee.Blocks) > 0 {
+				continue
+			}
+			if _, ok := common.Value.(*ssa.Builtin); ok {
+				continue
+			}
+			for _, arg := range common.Args {
+				if arg != addr && q.tainted(arg) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// taintedParameter checks the arguments passed by all the callers of the function.
+// The parameters of the functions whose callers are not all known are tainted.
+func (q *query) taintedParameter(p *ssa.Parameter) bool {
+	fn := p.Parent()
+	if q.analyzer.unknownCallers(fn) {
+		return true
+	}
+	index := -1
+	for i, param := range fn.Params {
+		if param == p {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	for _, call := range q.analyzer.callers[fn] {
+		args := call.Common().Args
+		if index < len(args) && q.tainted(args[index]) {
+			return true
+		}
+	}
+	return false
+}
+
+// taintedFreeVar checks the values bound to the free variable of a closure
+func (q *query) taintedFreeVar(fv *ssa.FreeVar) bool {
+	fn := fv.Parent()
+	index := -1
+	for i, v := range fn.FreeVars {
+		if v == fv {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	for _, closure := range q.analyzer.closures[fn] {
+		if index < len(closure.Bindings) {
+			binding := closure.Bindings[index]
+			if q.tainted(binding) || q.taintedStores(binding) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// taintedCall checks the value returned by a call
+func (q *query) taintedCall(common *ssa.CallCommon) bool {
+	conf := q.analyzer.config
+	if builtin, ok := common.Value.(*ssa.Builtin); ok {
+		switch builtin.Name() {
+		case "len", "cap":
+			return false
+		}
+		return q.taintedArgs(common)
+	}
+
+	callee := common.StaticCallee()
+	if callee == nil {
+		if common.IsInvoke() {
+			name := fmt.Sprintf("(%s).%s", common.Value.Type().String(), common.Method.Name())
+			if conf.isSourceFunc(name) {
+				return true
+			}
+			if conf.isSanitizer(name) {
+				return false
+			}
+		}
+		return q.tainted(common.Value) || q.taintedArgs(common)
+	}
+
+	name := funcName(callee)
+	if conf.isSourceFunc(name) {
+		return true
+	}
+	if conf.isSanitizer(name) {
+		return false
+	}
+	if len(callee.Blocks) == 0 {
+		// the body is not available, hence the result depends on the arguments
+		return q.taintedArgs(common)
+	}
+	for _, block := range callee.Blocks {
+		for _, instr := range block.Instrs {
+			if ret, ok := instr.(*ssa.Return); ok {
+				for _, result := range ret.Results {
+					if q.tainted(result) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (q *query) taintedArgs(common *ssa.CallCommon) bool {
+	for _, arg := range common.Args {
+		if q.tainted(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcName returns the full name of a function, e.g. "os.Getenv" or
+// "(*net/http.Request).FormValue"
+func funcName(fn *ssa.Function) string {
+	if obj, ok := fn.Object().(*types.Func); ok {
+		return obj.FullName()
+	}
+	return fn.String()
+}
+
+// globalName returns the full name of a global, e.g. "os.Args"
+func globalName(g *ssa.Global) string {
+	if g.Pkg != nil && g.Pkg.Pkg != nil {
+		return g.Pkg.Pkg.Path() + "." + g.Name()
+	}
+	return g.Name()
+}
@@ -0,0 +1,13 @@
+package taint_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTaint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Taint Suite")
+}
@@ -0,0 +1,256 @@
+package taint_test
+
+import (
+	"go/ast"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/securego/gosec/v2/taint"
+	"github.com/securego/gosec/v2/testutils"
+)
+
+var _ = Describe("Taint analysis", func() {
+	var pkg *testutils.TestPackage
+
+	// analyze builds the source and returns the analyzer along with the calls
+	// to the function with the given name, in source order
+	analyze := func(source string, name string) (*taint.Analyzer, []*ast.CallExpr) {
+		pkg = testutils.NewTestPackage()
+		pkg.AddFile("main.go", source)
+		Expect(pkg.Build()).ShouldNot(HaveOccurred())
+		p := pkg.Pkgs()[0]
+		analyzer := taint.New(nil, p.Fset, p.Types, p.Syntax, p.TypesInfo)
+		var calls []*ast.CallExpr
+		for _, file := range p.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == name {
+						calls = append(calls, call)
+					}
+				}
+				return true
+			})
+		}
+		return analyzer, calls
+	}
+
+	AfterEach(func() {
+		pkg.Close()
+	})
+
+	It("should report data flowing from a source through a function call", func() {
+		analyzer, calls := analyze(`
+package main
+import (
+	"os"
+	"os/exec"
+)
+func binary() string {
+	return os.Getenv("BINARY")
+}
+func main() {
+	cmd := binary()
+	_ = exec.Command(cmd).Run()
+}`, "Command")
+		Expect(calls).To(HaveLen(1))
+		tainted, err := analyzer.IsTainted(calls[0], 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeTrue())
+	})
+
+	It("should not report constants passed through variables and functions", func() {
+		analyzer, calls := analyze(`
+package main
+import "os/exec"
+func binary() string {
+	return "ls"
+}
+func main() {
+	cmd := binary()
+	args := []string{"-l", "-a"}
+	_ = exec.Command(cmd, args...).Run()
+}`, "Command")
+		Expect(calls).To(HaveLen(1))
+		for i := range calls[0].Args {
+			tainted, err := analyzer.IsTainted(calls[0], i)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tainted).To(BeFalse())
+		}
+	})
+
+	It("should follow struct fields and the parameters of an http handler", func() {
+		analyzer, calls := analyze(`
+package main
+import (
+	"net/http"
+	"os"
+)
+type config struct {
+	path string
+}
+func handler(w http.ResponseWriter, r *http.Request) {
+	c := config{}
+	c.path = r.URL.Query().Get("file")
+	f, _ := os.Open(c.path)
+	f.Close()
+}
+func main() {
+	http.HandleFunc("/", handler)
+}`, "Open")
+		Expect(calls).To(HaveLen(1))
+		tainted, err := analyzer.IsTainted(calls[0], 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeTrue())
+	})
+
+	It("should stop at sanitizers", func() {
+		analyzer, calls := analyze(`
+package main
+import (
+	"os"
+	"path/filepath"
+)
+func main() {
+	f, _ := os.Open(filepath.Base(os.Args[1]))
+	f.Close()
+}`, "Open")
+		Expect(calls).To(HaveLen(1))
+		tainted, err := analyzer.IsTainted(calls[0], 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeFalse())
+	})
+
+	It("should follow the variadic arguments and closures", func() {
+		analyzer, calls := analyze(`
+package main
+import (
+	"os"
+	"os/exec"
+)
+func main() {
+	arg := os.Args[1]
+	run := func() {
+		_ = exec.Command("ls", "-l", arg).Run()
+	}
+	run()
+}`, "Command")
+		Expect(calls).To(HaveLen(1))
+		tainted, err := analyzer.IsTainted(calls[0], 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeFalse())
+		tainted, err = analyzer.IsTainted(calls[0], 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeTrue())
+	})
+
+	It("should report the parameters of the functions with unknown callers", func() {
+		analyzer, calls := analyze(`
+package main
+import "os/exec"
+type runner interface {
+	start(name string)
+}
+type local struct{}
+func (local) start(name string) {
+	_ = exec.Command(name).Start()
+}
+func Run(name string) {
+	_ = exec.Command(name).Run()
+}
+func callback(name string) {
+	_ = exec.Command(name).Run()
+}
+func internal(name string) {
+	_ = exec.Command(name).Run()
+}
+func main() {
+	var r runner = local{}
+	r.start("ls")
+	apply(callback)
+	internal("ls")
+}
+func apply(f func(string)) {
+	f("ls")
+}`, "Command")
+		Expect(calls).To(HaveLen(4))
+		var results []bool
+		for _, call := range calls {
+			tainted, err := analyzer.IsTainted(call, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			results = append(results, tainted)
+		}
+		Expect(results).To(Equal([]bool{true, true, true, false}))
+	})
+
+	It("should use the custom sources", func() {
+		_, calls := analyze(`
+package main
+import "os/exec"
+func input() string {
+	return "ls"
+}
+func main() {
+	_ = exec.Command(input()).Run()
+}`, "Command")
+		conf := taint.DefaultConfig()
+		conf.Merge(&taint.Config{SourceFuncs: []string{"command-line-arguments.input"}})
+		p := pkg.Pkgs()[0]
+		analyzer := taint.New(conf, p.Fset, p.Types, p.Syntax, p.TypesInfo)
+		tainted, err := analyzer.IsTainted(calls[0], 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeTrue())
+	})
+
+	It("should return the checked arguments of the sinks", func() {
+		analyzer, calls := analyze(`
+package main
+import (
+	"context"
+	"os/exec"
+)
+func main() {
+	_ = exec.CommandContext(context.Background(), "ls", "-l", "-a").Run()
+}`, "CommandContext")
+		Expect(calls).To(HaveLen(1))
+		args, ok := analyzer.SinkArgs("G204", calls[0])
+		Expect(ok).To(BeTrue())
+		Expect(args).To(Equal([]int{1, 2, 3}))
+		_, ok = analyzer.SinkArgs("G304", calls[0])
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should use the custom sinks", func() {
+		_, calls := analyze(`
+package main
+import "os"
+func run(dir string, name string) {
+}
+func main() {
+	run("/tmp", os.Args[1])
+}`, "")
+		conf := taint.DefaultConfig()
+		conf.Merge(&taint.Config{Sinks: map[string]map[string][]int{
+			"G204": {"command-line-arguments.run": {1}},
+		}})
+		p := pkg.Pkgs()[0]
+		analyzer := taint.New(conf, p.Fset, p.Types, p.Syntax, p.TypesInfo)
+		Expect(calls).To(BeEmpty())
+		var call *ast.CallExpr
+		ast.Inspect(p.Syntax[0], func(n ast.Node) bool {
+			if c, ok := n.(*ast.CallExpr); ok {
+				if ident, ok := c.Fun.(*ast.Ident); ok && ident.Name == "run" {
+					call = c
+				}
+			}
+			return true
+		})
+		Expect(call).ShouldNot(BeNil())
+		args, ok := analyzer.SinkArgs("G204", call)
+		Expect(ok).To(BeTrue())
+		Expect(args).To(Equal([]int{1}))
+		tainted, err := analyzer.IsTainted(call, args[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tainted).To(BeTrue())
+	})
+})
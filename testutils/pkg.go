@@ -114,6 +114,7 @@ func (p *TestPackage) CreateContext(filename string) *gosec.Context {
 					Config:       gosec.NewConfig(),
 					Info:         pkg.TypesInfo,
 					Pkg:          pkg.Types,
+					PkgFiles:     pkg.Syntax,
 					Imports:      gosec.NewImportTracker(),
 					PassedValues: make(map[string]interface{}),
 				}
@@ -21,6 +21,20 @@ func main() {
 	fmt.Println("Doing something with: ", username, password)
 }`}, 1, gosec.NewConfig()},
 		{[]string{`
+package main
+import "fmt"
+func main() {
+	password, apiToken := "f62e5bcda4fae4f82370da0c6f20697b8f8447ef", "ad9a8db1ee8bd3a1fe5df1cc4bc0d6c2"
+	fmt.Println("Doing something with: ", password, apiToken)
+}`}, 2, gosec.NewConfig()},
+		{[]string{`
+package main
+import "fmt"
+func main() {
+	password, apiToken := "secret", "ad9a8db1ee8bd3a1fe5df1cc4bc0d6c2"
+	fmt.Println("Doing something with: ", password, apiToken)
+}`}, 1, gosec.NewConfig()},
+		{[]string{`
 // Entropy check should not report this error by default
 package main
 import "fmt"
@@ -645,8 +659,75 @@ func get(url string) {
 func main() {
 	url := "http://127.0.0.1"
 	get(url)
+}`}, 1, gosec.NewConfig()}, {[]string{`
+package main
+import (
+	"fmt"
+	"net/http"
+)
+func target() string {
+	return "https://www.google.com"
+}
+func main() {
+	url := target()
+	resp, err := http.Get(url)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
+}`}, 0, gosec.NewConfig()}, {[]string{`
+package main
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+func target() string {
+	if url := os.Getenv("TARGET"); url != "" {
+		return url
+	}
+	return "https://www.google.com"
+}
+func main() {
+	url := target()
+	resp, err := http.Get(url)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
 }`}, 1, gosec.NewConfig()}}
 
+	// SampleCodeG107Taint - SSRF via http requests with tainted url
+	SampleCodeG107Taint = []CodeSample{{[]string{`
+package main
+import (
+	"net/http"
+	"os"
+)
+func target() string {
+	return os.Getenv("TARGET")
+}
+func main() {
+	resp, err := http.Get(target())
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import "net/http"
+func target() string {
+	return "https://www.google.com"
+}
+func main() {
+	url := target()
+	resp, err := http.Get(url)
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+}`}, 0, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}}
+
 	// SampleCodeG108 - pprof endpoint automatically exposed
 	SampleCodeG108 = []CodeSample{{[]string{`
 package main
@@ -1064,6 +1145,46 @@ func main(){
 }`}, 0, gosec.NewConfig()},
 	}
 
+	// SampleCodeG201Taint - SQL injection via format string with tainted input
+	SampleCodeG201Taint = []CodeSample{{[]string{`
+package main
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+)
+func handler(db *sql.DB, r *http.Request) {
+	q := fmt.Sprintf("SELECT * FROM foo where name = '%s'", r.FormValue("name"))
+	rows, err := db.Query(q)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+}
+func main() {
+	handler(nil, nil)
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import (
+	"database/sql"
+	"fmt"
+)
+func table() string {
+	return "foo"
+}
+func main() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	q := fmt.Sprintf("SELECT * FROM %s", table())
+	rows, err := db.Query(q)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+}`}, 0, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}}
+
 	// SampleCodeG202 - SQL query string building via string concatenation
 	SampleCodeG202 = []CodeSample{
 		{[]string{`
@@ -1461,9 +1582,127 @@ func main() {
 	}
 	log.Printf("Command finished with error: %v", err)
 }
+`}, 1, gosec.NewConfig()},
+		{[]string{`
+package main
+import (
+	"log"
+	"os/exec"
+)
+const shell = "/bin/sh"
+func binary() string {
+	return shell
+}
+func main() {
+	err := exec.Command(binary(), "-c", "ls").Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`}, 0, gosec.NewConfig()},
+		{[]string{`
+package main
+import (
+	"log"
+	"os/exec"
+)
+func binary(n int) string {
+	if n <= 0 {
+		return "ls"
+	}
+	return binary(n - 1)
+}
+func main() {
+	err := exec.Command(binary(2)).Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 `}, 1, gosec.NewConfig()},
 	}
 
+	// SampleCodeG204Taint - Subprocess launched with tainted input
+	SampleCodeG204Taint = []CodeSample{{[]string{`
+package main
+import (
+	"os"
+	"os/exec"
+)
+type command struct {
+	name string
+}
+func main() {
+	c := command{name: os.Args[1]}
+	err := exec.Command(c.name).Run()
+	if err != nil {
+		panic(err)
+	}
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import "os/exec"
+func binary() string {
+	return "ls"
+}
+func main() {
+	name := binary()
+	args := []string{"-l"}
+	err := exec.Command(name, args...).Run()
+	if err != nil {
+		panic(err)
+	}
+}`}, 0, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import "os/exec"
+func Run(name string) error {
+	return exec.Command(name).Run()
+}
+func main() {
+	_ = Run("ls")
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import (
+	"net/http"
+	"os/exec"
+)
+func handler(w http.ResponseWriter, r *http.Request) {
+	_ = exec.CommandContext(r.Context(), "ls").Run()
+}
+func main() {
+	http.HandleFunc("/", handler)
+}`}, 0, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import "os"
+func main() {
+	p, err := os.StartProcess(os.Args[1], os.Args[1:], &os.ProcAttr{})
+	if err != nil {
+		panic(err)
+	}
+	_, _ = p.Wait()
+}`}, 1, gosec.Config{
+		gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"},
+		"taint": map[string]interface{}{
+			"sinks": map[string]interface{}{
+				"G204": map[string]interface{}{"os.StartProcess": []int{0, 1}},
+			},
+		},
+	}}, {[]string{`
+package main
+import "os"
+func main() {
+	p, err := os.StartProcess("/bin/ls", []string{"ls", "-l"}, &os.ProcAttr{})
+	if err != nil {
+		panic(err)
+	}
+	_, _ = p.Wait()
+}`}, 0, gosec.Config{
+		gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"},
+		"taint": map[string]interface{}{
+			"sinks": map[string]interface{}{
+				"G204": map[string]interface{}{"os.StartProcess": []int{0, 1}},
+			},
+		},
+	}}}
+
 	// SampleCodeG301 - mkdir permission check
 	SampleCodeG301 = []CodeSample{{[]string{`
 package main
@@ -1763,7 +2002,90 @@ func main() {
     }
 }
 
-`}, 0, gosec.NewConfig()}}
+`}, 0, gosec.NewConfig()}, {[]string{`
+package main
+
+import (
+	"os"
+)
+
+type config struct{}
+
+func (c config) path() string {
+	return "/etc/app/" + "config.yaml"
+}
+
+func main() {
+	path := config{}.path()
+	f, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	f.Close()
+}
+`}, 0, gosec.NewConfig()}, {[]string{`
+package main
+
+import (
+	"os"
+)
+
+func path(name string) string {
+	return "/etc/app/" + name
+}
+
+func main() {
+	p := path(os.Args[1])
+	f, err := os.Open(p)
+	if err != nil {
+		panic(err)
+	}
+	f.Close()
+}
+`}, 1, gosec.NewConfig()}}
+
+	// SampleCodeG304Taint - File path provided as tainted input
+	SampleCodeG304Taint = []CodeSample{{[]string{`
+package main
+import (
+	"io/ioutil"
+	"net/http"
+)
+func handler(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadFile(r.URL.Query().Get("file"))
+	if err != nil {
+		panic(err)
+	}
+	w.Write(data)
+}
+func main() {
+	http.HandleFunc("/", handler)
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import "os"
+func configPath() string {
+	return "/etc/app/config.yaml"
+}
+func main() {
+	path := configPath()
+	f, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	f.Close()
+}`}, 0, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}, {[]string{`
+package main
+import (
+	"os"
+	"path/filepath"
+)
+func main() {
+	f, err := os.Open(filepath.Clean(os.Args[1]))
+	if err != nil {
+		panic(err)
+	}
+	f.Close()
+}`}, 1, gosec.Config{gosec.Globals: map[gosec.GlobalOption]string{gosec.TaintAnalysis: "enabled"}}}}
 
 	// SampleCodeG305 - File path traversal when extracting zip/tar archives
 	SampleCodeG305 = []CodeSample{{[]string{`
@@ -2109,7 +2431,7 @@ func main() {
 	if err != nil {
 		fmt.Println(err)
 	}
-}`}, 1, gosec.NewConfig()}, {[]string{
+}`}, 2, gosec.NewConfig()}, {[]string{
 		`
 // Insecure minimum version
 package main
@@ -2167,7 +2489,7 @@ func main() {
 	if err != nil {
 		fmt.Println(err)
 	}
-}`}, 1, gosec.NewConfig(),
+}`}, 2, gosec.NewConfig(),
 	}, {[]string{`
 // secure max version when min version is specified
 package main
@@ -2202,7 +2524,28 @@ import "crypto/tls"
 func TlsConfig1() *tls.Config {
    return &tls.Config{MinVersion: 0x0304}
 }
-`}, 1, gosec.NewConfig()}}
+`}, 1, gosec.NewConfig()}, {[]string{`
+// Several insecure settings in the same configuration
+package main
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+func main() {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			CipherSuites:       []uint16{tls.TLS_RSA_WITH_RC4_128_SHA},
+			MinVersion:         tls.VersionTLS10,
+		},
+	}
+	client := &http.Client{Transport: tr}
+	_, err := client.Get("https://golang.org/")
+	if err != nil {
+		fmt.Println(err)
+	}
+}`}, 3, gosec.NewConfig()}}
 
 	// SampleCodeG403 - weak key strength
 	SampleCodeG403 = []CodeSample{